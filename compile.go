@@ -0,0 +1,114 @@
+package stragts
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CompiledTag is a tag value parsed once and ready to fill any number
+// of option structs without lexing or parsing the value again.
+// A CompiledTag is immutable and safe for concurrent use.
+type CompiledTag struct {
+	value string
+	p     *parsed // nil if the value is just a dash.
+}
+
+// Compile parses the given tag value into a CompiledTag.
+func Compile(value string) (*CompiledTag, error) {
+	// Skip parsing the tag value if it is just a dash.
+	if value == "-" {
+		return &CompiledTag{value: value}, nil
+	}
+
+	p, err := parseValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledTag{value: value, p: p}, nil
+}
+
+// String returns the tag value ct was compiled from.
+func (ct *CompiledTag) String() string { return ct.value }
+
+// Fill fills the option struct model points to with the values of
+// the compiled tag. See Tag.Fill.
+func (ct *CompiledTag) Fill(model any) error {
+	return defaultDecoder.FillCompiled(ct, model)
+}
+
+// compiledCacheSize is the number of compiled tags retained by Tag.Fill.
+const compiledCacheSize = 1024
+
+// compiledCache holds the tags most recently compiled by Tag.Fill.
+var compiledCache = newLRUCache(compiledCacheSize)
+
+// compileCached returns the compiled tag for the given value, reusing
+// a previous compilation of the same value if possible.
+func compileCached(value string) (*CompiledTag, error) {
+	if ct, ok := compiledCache.get(value); ok {
+		return ct, nil
+	}
+	ct, err := Compile(value)
+	if err != nil {
+		return nil, err
+	}
+	compiledCache.add(value, ct)
+	return ct, nil
+}
+
+// lruCache is a bounded cache of compiled tags that evicts the least
+// recently used entry once full. It is safe for concurrent use.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List               // entries, most recently used first.
+	items    map[string]*list.Element // entries by tag value.
+}
+
+type lruEntry struct {
+	key string
+	ct  *CompiledTag
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{capacity: capacity, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+// get returns the cached compiled tag for key.
+func (c *lruCache) get(key string) (*CompiledTag, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*lruEntry).ct, true
+	}
+	return nil, false
+}
+
+// add stores the compiled tag for key, evicting the least recently
+// used entry if the cache is full.
+func (c *lruCache) add(key string, ct *CompiledTag) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*lruEntry).ct = ct
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, ct: ct})
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// len returns the number of cached entries.
+func (c *lruCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
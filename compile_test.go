@@ -0,0 +1,97 @@
+package stragts
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Index    string
+		Priority int
+		Unique   bool
+	}
+
+	ct, err := Compile("idx_member,priority=2,~unique")
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("idx_member,priority=2,~unique", ct.String())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var v TestStruct
+				if assert.NoError(ct.Fill(&v)) {
+					assert.Equal(TestStruct{Index: "idx_member", Priority: 2, Unique: true}, v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	ct, err = Compile("-")
+	if assert.NoError(err) {
+		v := TestStruct{Index: "unchanged"}
+		assert.NoError(ct.Fill(&v))
+		assert.Equal("unchanged", v.Index)
+		assert.Error(ct.Fill(v))
+	}
+
+	_, err = Compile("a,,b")
+	assert.Error(err)
+}
+
+func TestLRUCache(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	c := newLRUCache(2)
+	for i := 0; i < 3; i++ {
+		c.add(strconv.Itoa(i), &CompiledTag{value: strconv.Itoa(i)})
+		if i == 1 {
+			// Make "0" the most recently used entry.
+			_, ok := c.get("0")
+			assert.True(ok)
+		}
+	}
+	assert.Equal(2, c.len())
+
+	_, ok := c.get("1")
+	assert.False(ok)
+	for _, key := range []string{"0", "2"} {
+		if ct, ok := c.get(key); assert.True(ok, key) {
+			assert.Equal(key, ct.String())
+		}
+	}
+}
+
+func BenchmarkCompiledTag_Fill(b *testing.B) {
+	type TestStruct struct {
+		Index       string
+		Priority    int
+		Unique      bool
+		StringField string
+		Slice       []string
+	}
+
+	ct, err := Compile("idx_member,2,~unique,string-field='hello world',slice=a;b")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v TestStruct
+		if err := ct.Fill(&v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
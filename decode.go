@@ -0,0 +1,366 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// decodeFunc stores the value held by n in v.
+type decodeFunc func(v reflect.Value, n node) error
+
+// decoder returns the value decoder for the type t.
+func (d *Decoder) decoder(t reflect.Type) decodeFunc {
+	if fn, ok := d.decoders.Load(t); ok {
+		return fn.(decodeFunc)
+	}
+
+	// To deal with recursive types, populate the cache with an
+	// indirect func before building the decoder itself.
+	var (
+		wg sync.WaitGroup
+		fn decodeFunc
+	)
+	wg.Add(1)
+	indirect, loaded := d.decoders.LoadOrStore(t, decodeFunc(func(v reflect.Value, n node) error {
+		wg.Wait()
+		return fn(v, n)
+	}))
+	if loaded {
+		return indirect.(decodeFunc)
+	}
+
+	fn = d.newDecoder(t)
+	wg.Done()
+	d.decoders.Store(t, fn)
+	return fn
+}
+
+func (d *Decoder) newDecoder(t reflect.Type) decodeFunc {
+	fn := d.newKindDecoder(t)
+
+	hooks := d.converters[t][:len(d.converters[t]):len(d.converters[t])]
+	if hook := registeredEnumHook(t); hook != nil {
+		hooks = append(hooks, hook)
+	}
+	hooks = append(hooks, d.hooks...)
+	if len(hooks) == 0 {
+		return fn
+	}
+	return func(v reflect.Value, n node) error {
+		n, done, err := runHooks(hooks, v, n)
+		if err != nil || done {
+			return err
+		}
+		return fn(v, n)
+	}
+}
+
+func (d *Decoder) newKindDecoder(t reflect.Type) decodeFunc {
+	if f := d.flagsFor(t); f != nil {
+		return f.decode
+	}
+	if t == valueType {
+		return decodeValue
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return d.decodeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return d.decodeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return d.decodeUint
+	case reflect.Float32, reflect.Float64:
+		return d.decodeFloat
+	case reflect.String:
+		return d.decodeString
+	case reflect.Pointer:
+		return d.newPointerDecoder(t)
+	case reflect.Slice:
+		return d.newSliceDecoder(t)
+	case reflect.Array:
+		return d.newArrayDecoder(t)
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return decodeAny
+		}
+	}
+	return func(v reflect.Value, n node) error {
+		if d.decodeNil(v, n) {
+			return nil
+		}
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+}
+
+// runHooks passes n through the given chain of decode hooks. It
+// reports whether a hook has stored a value in v already.
+func runHooks(hooks []DecodeHook, v reflect.Value, n node) (node, bool, error) {
+	for _, hook := range hooks {
+		out, err := hook(Value{n}, v.Type())
+		if err != nil {
+			return nil, false, err
+		}
+		if value, ok := out.(Value); ok && value.n != nil {
+			n = value.n
+			continue
+		}
+		return nil, true, assign(v, out)
+	}
+	return n, false, nil
+}
+
+// decodeNil stores the zero value in v if n is the nil constant and
+// reports whether it has done so.
+func (d *Decoder) decodeNil(v reflect.Value, n node) bool {
+	if n.getType() != nodeNil || (d.strict && !isNilable(v.Type())) {
+		return false
+	}
+	v.Set(reflect.Zero(v.Type()))
+	return true
+}
+
+// decodeValue stores n as it is in v of the type Value.
+func decodeValue(v reflect.Value, n node) error {
+	v.Set(reflect.ValueOf(Value{n}))
+	return nil
+}
+
+// decodeAny stores the natural Go value of n in v of an empty
+// interface type. See Value.Interface.
+func decodeAny(v reflect.Value, n node) error {
+	if (Value{n}).hasSwitchValue() {
+		return mismatch(v, n)
+	}
+	return assign(v, Value{n}.Interface())
+}
+
+func (d *Decoder) decodeBool(v reflect.Value, n node) error {
+	if d.decodeNil(v, n) {
+		return nil
+	}
+	switch nv := n.(type) {
+	case *boolNode:
+		v.SetBool(nv.value)
+	case *switchNode:
+		// Switches within values only have a meaning for flags.
+		if !nv.argument {
+			return mismatch(v, n)
+		}
+		v.SetBool(nv.value.value)
+	default:
+		return mismatch(v, n)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeInt(v reflect.Value, n node) error {
+	if d.decodeNil(v, n) {
+		return nil
+	}
+	switch nv := n.(type) {
+	case *numberNode:
+		if !nv.IsInt {
+			return mismatch(v, n)
+		}
+		if v.OverflowInt(nv.Int64) {
+			return overflow(v, n)
+		}
+		v.SetInt(nv.Int64)
+	default:
+		return mismatch(v, n)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeUint(v reflect.Value, n node) error {
+	if d.decodeNil(v, n) {
+		return nil
+	}
+	switch nv := n.(type) {
+	case *numberNode:
+		if !nv.IsUint {
+			return mismatch(v, n)
+		}
+		if v.OverflowUint(nv.Uint64) {
+			return overflow(v, n)
+		}
+		v.SetUint(nv.Uint64)
+	default:
+		return mismatch(v, n)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeFloat(v reflect.Value, n node) error {
+	if d.decodeNil(v, n) {
+		return nil
+	}
+	switch nv := n.(type) {
+	case *numberNode:
+		if !nv.IsFloat {
+			return mismatch(v, n)
+		}
+		if v.OverflowFloat(nv.Float64) {
+			return overflow(v, n)
+		}
+		v.SetFloat(nv.Float64)
+	default:
+		return mismatch(v, n)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeString(v reflect.Value, n node) error {
+	if d.decodeNil(v, n) {
+		return nil
+	}
+	switch nv := n.(type) {
+	case *identifierNode:
+		if d.strict {
+			return mismatch(v, n)
+		}
+		v.SetString(nv.value)
+	case *stringNode:
+		v.SetString(nv.Text)
+	default:
+		return mismatch(v, n)
+	}
+	return nil
+}
+
+func (d *Decoder) newPointerDecoder(t reflect.Type) decodeFunc {
+	elem := d.decoder(t.Elem())
+	return func(v reflect.Value, n node) error {
+		if n.getType() == nodeNil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return elem(v.Elem(), n)
+	}
+}
+
+func (d *Decoder) newSliceDecoder(t reflect.Type) decodeFunc {
+	elem := d.decoder(t.Elem())
+	return func(v reflect.Value, n node) error {
+		var values []node
+		switch nv := n.(type) {
+		case *nilNode:
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		case *sliceNode:
+			values = nv.values
+		default:
+			// A single value is a slice of length one.
+			if d.strict {
+				return mismatch(v, n)
+			}
+			values = []node{n}
+		}
+
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, el := range values {
+			if err := elem(s.Index(i), el); err != nil {
+				return fmt.Errorf("element #%d: %w", i, err)
+			}
+		}
+		if d.merge {
+			s = reflect.AppendSlice(v, s)
+		}
+		v.Set(s)
+		return nil
+	}
+}
+
+func (d *Decoder) newArrayDecoder(t reflect.Type) decodeFunc {
+	elem := d.decoder(t.Elem())
+	return func(v reflect.Value, n node) error {
+		if d.decodeNil(v, n) {
+			return nil
+		}
+		var values []node
+		switch nv := n.(type) {
+		case *sliceNode:
+			values = nv.values
+		default:
+			// A single value is a list of length one.
+			if d.strict {
+				return mismatch(v, n)
+			}
+			values = []node{n}
+		}
+		if len(values) != v.Len() {
+			return newKindError(ErrTypeMismatch, "cannot use %d values as %s", len(values), v.Type())
+		}
+
+		// Decode into a copy to leave v untouched on errors.
+		a := reflect.New(v.Type()).Elem()
+		for i, el := range values {
+			if err := elem(a.Index(i), el); err != nil {
+				return fmt.Errorf("element #%d: %w", i, err)
+			}
+		}
+		v.Set(a)
+		return nil
+	}
+}
+
+// fieldByIndex returns the nested field of v corresponding to index,
+// allocating nil pointers to embedded structs on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// assign stores the Go value x in v.
+func assign(v reflect.Value, x any) error {
+	if x == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	xv := reflect.ValueOf(x)
+	switch {
+	case xv.Type().AssignableTo(v.Type()):
+		v.Set(xv)
+	case xv.Kind() == v.Kind() && xv.Type().ConvertibleTo(v.Type()):
+		v.Set(xv.Convert(v.Type()))
+	default:
+		return newKindError(ErrTypeMismatch, "cannot use %T as %s", x, v.Type())
+	}
+	return nil
+}
+
+// isNilable reports whether nil is a valid value of the type t.
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// mismatch returns the error for a value n not matching the type of v.
+func mismatch(v reflect.Value, n node) error {
+	return newKindError(ErrTypeMismatch, "cannot use %s as %s", n, v.Type())
+}
+
+// overflow returns the error for a number n not fitting into v.
+func overflow(v reflect.Value, n node) error {
+	return newKindError(ErrOverflow, "%s overflows %s", n, v.Type())
+}
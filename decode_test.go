@@ -0,0 +1,394 @@
+package stragts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestTag_Fill_Decode(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type Embedded struct {
+		Inner string
+	}
+
+	type TestStruct struct {
+		Int8    int8
+		Uint    uint
+		Float32 float32
+		Ptr     *int
+		Names   []string
+		*Embedded
+	}
+
+	v := &TestStruct{}
+	assert.NoError(Tag{Value: "-12,12,1.5,3,single,inner=x"}.Fill(v))
+	assert.Equal(int8(-12), v.Int8)
+	assert.Equal(uint(12), v.Uint)
+	assert.Equal(float32(1.5), v.Float32)
+	assert.Equal(3, *v.Ptr)
+	assert.Equal([]string{"single"}, v.Names)
+	assert.Equal("x", v.Inner)
+
+	ptr := v.Ptr
+	assert.NoError(Tag{Value: "ptr=4"}.Fill(v))
+	assert.Same(ptr, v.Ptr)
+	assert.Equal(4, *v.Ptr)
+
+	assert.NoError(Tag{Value: "ptr=nil,names=nil"}.Fill(v))
+	assert.Nil(v.Ptr)
+	assert.Nil(v.Names)
+
+	for _, value := range []string{
+		"128",
+		"0,-1",
+		"0,0,1e40",
+		"0,0,0,'x'",
+		"names=1;2",
+		"uint=1.5",
+		"unknown=1",
+		"0,0,0,0,x,x,x",
+	} {
+		assert.Error(Tag{Value: value}.Fill(&TestStruct{}), "Fill(%v)", value)
+	}
+}
+
+func TestTag_Fill_Embedded(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type CommonOpts struct {
+		Name     string
+		Priority int
+	}
+	type Extra struct {
+		Priority int
+		Comment  string
+	}
+	type IndexOpts struct {
+		CommonOpts
+		Unique bool
+		*Extra
+	}
+
+	v := &IndexOpts{}
+	assert.NoError(Tag{Value: "idx,2,true,3,comment=x"}.Fill(v))
+	assert.Equal(IndexOpts{
+		CommonOpts: CommonOpts{Name: "idx", Priority: 2},
+		Unique:     true,
+		Extra:      &Extra{Priority: 3, Comment: "x"},
+	}, *v)
+
+	md, err := Tag{Value: "idx,comment=x"}.FillMetadata(v)
+	if assert.NoError(err) {
+		assert.True(md.IsSet("CommonOpts.Name"))
+		assert.True(md.IsSet("Extra.Comment"))
+	}
+
+	err = Tag{Value: "priority=1"}.Fill(v)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), `ambiguous key "priority"`)
+	}
+
+	type Shadowing struct {
+		Priority string
+		IndexOpts
+	}
+	s := &Shadowing{}
+	assert.NoError(Tag{Value: "high,idx,priority=low"}.Fill(s))
+	assert.Equal("low", s.Priority)
+	assert.Equal("idx", s.Name)
+	assert.Equal(0, s.CommonOpts.Priority)
+
+	// Shallower fields hide deeper ones regardless of declaration order.
+	type Inner struct {
+		X int
+		P string
+	}
+	type Other struct {
+		P string
+	}
+	type ShadowingLater struct {
+		Inner
+		Other
+		X int
+		P string
+	}
+	sl := &ShadowingLater{}
+	assert.NoError(Tag{Value: "x=5, p=hi"}.Fill(sl))
+	assert.Equal(5, sl.X)
+	assert.Equal("hi", sl.P)
+	assert.Equal(Inner{}, sl.Inner)
+	assert.Equal(Other{}, sl.Other)
+
+	// Opaque embedded structs are single fields, unexported fields take
+	// no positional slot.
+	type opaque struct {
+		n int
+	}
+	type Stamped struct {
+		opaque
+		time.Time
+		hidden string
+		Name   string
+	}
+	d := NewDecoder(WithDecodeHook(TextUnmarshalerHook))
+	st := &Stamped{}
+	assert.NoError(d.Fill(Tag{Value: "'2024-01-02T00:00:00Z', x"}, st))
+	assert.Equal("x", st.Name)
+	assert.Equal(2024, st.Year())
+	assert.NoError(d.Fill(Tag{Value: "name=y, time='2025-01-02T00:00:00Z'"}, st))
+	assert.Equal("y", st.Name)
+	assert.Equal(2025, st.Year())
+	assert.Error(d.Fill(Tag{Value: "opaque=1"}, st))
+	assert.Error(d.Fill(Tag{Value: "wall=1"}, st))
+}
+
+func TestTag_Fill_DottedKeys(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type DBOpts struct {
+		Name   string `stragts:"required"`
+		Schema string `stragts:"default=public"`
+	}
+	type Opts struct {
+		Label string
+		DB    DBOpts
+		Dest  *struct {
+			DB *DBOpts
+		}
+	}
+
+	v := &Opts{}
+	md, err := Tag{Value: "x, db.name=users, dest.db.name=archive, dest.db.schema=old"}.FillMetadata(v)
+	if assert.NoError(err) {
+		assert.Equal("x", v.Label)
+		assert.Equal(DBOpts{Name: "users", Schema: "public"}, v.DB)
+		if assert.NotNil(v.Dest) && assert.NotNil(v.Dest.DB) {
+			assert.Equal(DBOpts{Name: "archive", Schema: "old"}, *v.Dest.DB)
+		}
+
+		src, ok := md.Lookup("Dest.DB.Schema")
+		if assert.True(ok) {
+			assert.Equal("dest.db.schema", src.Key)
+			assert.Equal([]int{2, 0, 1}, src.Index)
+			assert.Equal(40, src.Pos)
+		}
+		assert.True(md.IsSet("DB.Name"))
+	}
+
+	for _, value := range []string{
+		"db.schema=x",
+		"db.name=1",
+		"db.nmae=x",
+		"label.name=x",
+	} {
+		assert.Error(Tag{Value: value}.Fill(&Opts{}), "Fill(%v)", value)
+	}
+}
+
+func TestTag_Fill_Any(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	var v struct {
+		First  any
+		Second interface{}
+		Opts   map[string]any
+	}
+	assert.NoError(Tag{Value: "x, 'y', opts=nil"}.Fill(&v))
+	assert.Equal("x", v.First)
+	assert.Equal("y", v.Second)
+	assert.Nil(v.Opts)
+
+	var m map[string]any
+	assert.NoError(Tag{Value: "int=-3, float=1.5, big=18446744073709551615, ~on, !off, s='a', id=b, none=nil, list=1;'x';nil"}.Fill(&m))
+	assert.Equal(map[string]any{
+		"int":   int64(-3),
+		"float": 1.5,
+		"big":   float64(18446744073709551615),
+		"on":    true,
+		"off":   false,
+		"s":     "a",
+		"id":    "b",
+		"none":  nil,
+		"list":  []any{int64(1), "x", nil},
+	}, m)
+
+	var ints map[string]int
+	md, err := Tag{Value: "a=1, b=2"}.FillMetadata(&ints)
+	if assert.NoError(err) {
+		assert.Equal(map[string]int{"a": 1, "b": 2}, ints)
+		assert.Equal([]FieldSource{{Key: "a", Arg: -1}, {Key: "b", Arg: -1, Pos: 5}}, md.Fields)
+	}
+
+	// Every fill replaces the map, unless merging.
+	assert.NoError(Tag{Value: "c=3"}.Fill(&ints))
+	assert.Equal(map[string]int{"c": 3}, ints)
+	assert.NoError(NewDecoder(Merge()).Fill(Tag{Value: "a=1, c=4"}, &ints))
+	assert.Equal(map[string]int{"a": 1, "c": 4}, ints)
+
+	assert.Error(Tag{Value: "a=x"}.Fill(&ints))
+	assert.Error(Tag{Value: "x"}.Fill(&m))
+	assert.Error(Tag{Value: "a=1"}.Fill(&map[int]any{}))
+	assert.Error(Tag{Value: "a=1"}.Fill(m))
+}
+
+func TestTag_Fill_Containers(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Pair    [2]int
+		Single  [1]string
+		Names   []*string
+		List    *[]int
+		PtrPtr  **int
+		Matrix  [2]*[1]uint8
+		Strings *[]*string
+	}
+
+	v := &TestStruct{}
+	assert.NoError(Tag{Value: "1;2, x, a;b, 3;4, 5, 6;7, strings=c"}.Fill(v))
+	assert.Equal([2]int{1, 2}, v.Pair)
+	assert.Equal([1]string{"x"}, v.Single)
+	if assert.Len(v.Names, 2) {
+		assert.Equal("a", *v.Names[0])
+		assert.Equal("b", *v.Names[1])
+	}
+	if assert.NotNil(v.List) {
+		assert.Equal([]int{3, 4}, *v.List)
+	}
+	if assert.NotNil(v.PtrPtr) && assert.NotNil(*v.PtrPtr) {
+		assert.Equal(5, **v.PtrPtr)
+	}
+	if assert.NotNil(v.Matrix[0]) && assert.NotNil(v.Matrix[1]) {
+		assert.Equal([1]uint8{6}, *v.Matrix[0])
+		assert.Equal([1]uint8{7}, *v.Matrix[1])
+	}
+	if assert.NotNil(v.Strings) && assert.Len(*v.Strings, 1) {
+		assert.Equal("c", *(*v.Strings)[0])
+	}
+
+	assert.NoError(Tag{Value: "pair=nil, list=nil, ptr-ptr=nil"}.Fill(v))
+	assert.Equal([2]int{}, v.Pair)
+	assert.Nil(v.List)
+	assert.Nil(v.PtrPtr)
+
+	for _, value := range []string{
+		"1",
+		"1;2;3",
+		"pair=1;x",
+		"matrix=1;2;3",
+		"matrix=1;256",
+	} {
+		err := Tag{Value: value}.Fill(&TestStruct{Pair: [2]int{8, 9}})
+		assert.Error(err, "Fill(%v)", value)
+	}
+
+	v = &TestStruct{Pair: [2]int{8, 9}}
+	assert.Error(Tag{Value: "pair=1;x"}.Fill(v))
+	assert.Equal([2]int{8, 9}, v.Pair)
+
+	assert.Error(NewDecoder(Strict()).Fill(Tag{Value: "pair=nil"}, v))
+}
+
+func TestTag_Fill_Errors(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type DBOpts struct {
+		Name string
+	}
+	type TestStruct struct {
+		Num   int8
+		Name  string `stragts:"required"`
+		Level int    `stragts:"max=3"`
+		DB    DBOpts
+	}
+
+	err := Tag{Value: "300, level=4, unknown=1, db.name=1, db.x=y"}.Fill(&TestStruct{})
+
+	var list ErrorList
+	if !assert.ErrorAs(err, &list) {
+		return
+	}
+	assert.Equal([]string{
+		"argument #0: 300 overflows int8",
+		`unknown key "unknown"`,
+		"db: name: cannot use 1 as string",
+		`db: unknown key "x"`,
+		`missing required key "name"`,
+		`key "level" at position 11: 4 exceeds max=3`,
+	}, strings.Split(err.Error(), "\n"))
+
+	var keys []string
+	var positions []int
+	for _, err := range list {
+		var fe *FieldError
+		if assert.ErrorAs(err, &fe) {
+			keys = append(keys, fe.Key)
+			positions = append(positions, fe.Pos)
+		}
+	}
+	assert.Equal([]string{"num", "unknown", "db.name", "db.x", "name", "level"}, keys)
+	assert.Equal([]int{0, 14, 33, 39, -1, 11}, positions)
+
+	var fe *FieldError
+	if assert.ErrorAs(err, &fe) {
+		assert.Equal("Num", fe.Field)
+		assert.Equal(0, fe.Arg)
+	}
+
+	var m map[string]int
+	err = Tag{Value: "a=x, b=1, c=1.5"}.Fill(&m)
+	assert.Len(err, 2)
+	assert.Equal(map[string]int{"b": 1}, m)
+}
+
+func TestTag_Fill_SwitchValues(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Unique bool
+		Any    any
+		Ptr    *bool
+	}
+
+	v := &TestStruct{}
+	assert.NoError(Tag{Value: "~unique, ~any, ~ptr"}.Fill(v))
+	assert.True(v.Unique)
+	assert.Equal(true, v.Any)
+	if assert.NotNil(v.Ptr) {
+		assert.True(*v.Ptr)
+	}
+
+	// Switches within values only have a meaning for flags.
+	for _, value := range []string{
+		"unique=!other",
+		"unique=~other",
+		"any=~x",
+		"any=1;!x",
+		"ptr=!x",
+	} {
+		err := Tag{Value: value}.Fill(&TestStruct{})
+		assert.ErrorIs(err, ErrTypeMismatch, "Fill(%v)", value)
+	}
+
+	tree, err := Parse("~a, b=!c, d=1;~e")
+	if !assert.NoError(err) {
+		return
+	}
+	own, inner, list := Value{tree.root.nodes[0].value}, Value{tree.root.nodes[1].value}, Value{tree.root.nodes[2].value}
+
+	assert.Equal(KindBool, own.Kind())
+	b, ok := own.Bool()
+	assert.True(b && ok)
+	assert.Equal(true, own.Interface())
+
+	assert.Equal(KindSwitch, inner.Kind())
+	_, ok = inner.Bool()
+	assert.False(ok)
+	assert.Nil(inner.Interface())
+
+	assert.Equal([]any{int64(1), nil}, list.Interface())
+}
@@ -0,0 +1,217 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DecodeHook is invoked with every value before it is decoded into a
+// value of the type to. A hook either returns the Go value to store,
+// which must be assignable or convertible to the type to, or a Value
+// to continue decoding with. Returning from unchanged leaves the value
+// to the next hook or the built-in decoding.
+type DecodeHook func(from Value, to reflect.Type) (any, error)
+
+// Decoder decodes tag values into option structs according to its
+// configuration. A Decoder is safe for concurrent use and caches the
+// decode plans of all option struct types it has seen.
+type Decoder struct {
+	naming        func(fieldName string) string
+	strict        bool
+	ignoreUnknown bool
+	merge         bool
+	hooks         []DecodeHook
+	converters    map[reflect.Type][]DecodeHook
+	flags         map[reflect.Type]*flagsType
+
+	plans    sync.Map // map[reflect.Type]*structPlan
+	decoders sync.Map // map[reflect.Type]decodeFunc
+	presence sync.Map // map[any][]presence, see structPlan.
+}
+
+// Option configures a Decoder.
+type Option func(*Decoder)
+
+// WithNaming sets the function deriving the key of keyword arguments
+// from the names of option struct fields. KebabCase is used by default.
+func WithNaming(fn func(fieldName string) string) Option {
+	return func(d *Decoder) { d.naming = fn }
+}
+
+// Strict disables all implicit conversions. Identifiers are no longer
+// accepted as strings, single values are no longer accepted as lists
+// of length one and nil is only accepted by pointers, slices, maps and
+// interfaces.
+func Strict() Option {
+	return func(d *Decoder) { d.strict = true }
+}
+
+// IgnoreUnknownKeys makes keyword arguments that do not match any
+// field of the option struct to be ignored instead of reported.
+func IgnoreUnknownKeys() Option {
+	return func(d *Decoder) { d.ignoreUnknown = true }
+}
+
+// Merge makes repeated fills of the same option struct merge the
+// values of the tag into the values already present instead of
+// replacing them, for layering several tags onto one option struct.
+// Lists are appended to slices and keyword arguments are added to the
+// map of a remainder field or to a map filled directly. Fields set by
+// an earlier fill, even to their zero value, keep their value instead
+// of taking their default value and satisfy required fields. A default
+// value applied by an earlier fill is replaced by a later one. Map
+// fields other than remainder fields can only be set to nil, so there
+// is nothing to merge into them.
+//
+// A merging Decoder remembers which fields of each option struct it has
+// filled and keeps these option structs reachable, so use one Decoder
+// per set of layered tags.
+//
+// By default, slices, remainder maps and maps filled directly are
+// replaced. Pointers already allocated are reused either way.
+func Merge() Option {
+	return func(d *Decoder) { d.merge = true }
+}
+
+// WithDecodeHook appends a hook to the chain of decode hooks.
+func WithDecodeHook(hook DecodeHook) Option {
+	return func(d *Decoder) { d.hooks = append(d.hooks, hook) }
+}
+
+// WithConverter appends a converter to the chain of converters for
+// values decoded into the type to. Converters are decode hooks which
+// are only invoked for their exact type, before any generic decode
+// hooks are run.
+func WithConverter(to reflect.Type, converter DecodeHook) Option {
+	return func(d *Decoder) {
+		if d.converters == nil {
+			d.converters = map[reflect.Type][]DecodeHook{}
+		}
+		d.converters[to] = append(d.converters[to], converter)
+	}
+}
+
+// NewDecoder returns a new Decoder configured by the given options.
+func NewDecoder(opts ...Option) *Decoder {
+	d := &Decoder{naming: KebabCase}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// defaultDecoder is the decoder used by Tag.Fill.
+var defaultDecoder = NewDecoder()
+
+// Fill fills the option struct model points to with the values of tag.
+// Model may also point to a map with string keys, which receives the
+// keyword arguments of the tag as entries.
+//
+// Positional arguments fill the exported fields of the option struct in
+// order, keyword arguments fill the field whose name maps to their key.
+// The fields of embedded structs are flattened into the option struct:
+// positionally they take the place of the embedded field, and by key
+// they are promoted as in Go, so a field hides fields of the same key
+// nested deeper. Using a key shared by several fields of the same depth
+// is an error. Embedded structs without exported fields or implementing
+// encoding.TextUnmarshaler, such as time.Time, are not flattened but
+// filled as a single field named after their type.
+//
+// Dotted keys such as db.name address the fields of nested option
+// structs held by struct or pointer to struct fields, allocating nil
+// pointers on the way. The keys addressing a nested option struct fill
+// it as if they were a tag of its own, so required fields and defaults
+// of the nested option struct only apply if it is addressed at all.
+//
+// Fill does not stop at the first problem with the tag but reports all
+// of them together as an ErrorList. Problems with single arguments or
+// fields are reported as *FieldError, which tells their key and
+// position in the tag value.
+func (d *Decoder) Fill(tag Tag, model any) error {
+	m, err := fillTarget(model)
+	if err != nil {
+		return err
+	}
+
+	// Processing of the tag flags, reusing earlier compilations.
+	ct, err := compileCached(tag.Value)
+	if err != nil {
+		return err
+	}
+
+	return d.fill(m, ct, nil)
+}
+
+// FillCompiled fills the option struct model points to with the values
+// of the compiled tag ct.
+func (d *Decoder) FillCompiled(ct *CompiledTag, model any) error {
+	m, err := fillTarget(model)
+	if err != nil {
+		return err
+	}
+	return d.fill(m, ct, nil)
+}
+
+// FillMetadata is like Fill but also returns which fields have been
+// set by which argument of the tag, and which keyword arguments have
+// been ignored.
+func (d *Decoder) FillMetadata(tag Tag, model any) (*Metadata, error) {
+	m, err := fillTarget(model)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := compileCached(tag.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &Metadata{}
+	if err := d.fill(m, ct, md); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+func (d *Decoder) fill(m reflect.Value, ct *CompiledTag, md *Metadata) error {
+	// Nothing to do if the tag value is just a dash.
+	if ct.p == nil {
+		return nil
+	}
+	if m.Kind() == reflect.Map {
+		return d.fillMap(m, ct.p, md)
+	}
+	return d.plan(m.Type()).fill(m, ct.p, md)
+}
+
+// fillMap stores the keyword arguments of p as entries of the map m,
+// decoded into the element type of the map. The map is replaced unless
+// merging, then existing entries are kept unless overwritten. All
+// problems found are returned together as an ErrorList of *FieldError.
+func (d *Decoder) fillMap(m reflect.Value, p *parsed, md *Metadata) error {
+	if len(p.indexed) > 0 {
+		return fmt.Errorf("positional arguments cannot fill %s", m.Type())
+	}
+	if m.IsNil() || !d.merge {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	decode := d.decoder(m.Type().Elem())
+	var errs ErrorList
+	for _, arg := range p.keyword {
+		k := arg.ident.value
+		ev := reflect.New(m.Type().Elem()).Elem()
+		if err := decode(ev, arg.value); err != nil {
+			e := newFieldError(arg.value, err)
+			e.Key, e.prefix = k, k+": "
+			errs = append(errs, e)
+			continue
+		}
+		m.SetMapIndex(reflect.ValueOf(k).Convert(m.Type().Key()), ev)
+		if md != nil {
+			md.Fields = append(md.Fields, FieldSource{Key: k, Arg: -1, Pos: int(arg.pos)})
+		}
+	}
+	return errs.Err()
+}
@@ -0,0 +1,222 @@
+package stragts
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestDecoder_Naming(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		StringField string
+	}
+
+	d := NewDecoder(WithNaming(strings.ToLower))
+
+	var v TestStruct
+	assert.NoError(d.Fill(Tag{Value: "stringfield=x"}, &v))
+	assert.Equal("x", v.StringField)
+	assert.Error(d.Fill(Tag{Value: "string-field=x"}, &v))
+}
+
+func TestDecoder_Strict(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Name  string
+		Names []string
+		Num   int
+		Ptr   *int
+	}
+
+	d := NewDecoder(Strict())
+
+	var v TestStruct
+	assert.NoError(d.Fill(Tag{Value: "'x',names='a';'b',ptr=nil"}, &v))
+	assert.Equal(TestStruct{Name: "x", Names: []string{"a", "b"}}, v)
+
+	assert.Error(d.Fill(Tag{Value: "name=x"}, &v))
+	assert.Error(d.Fill(Tag{Value: "names='a'"}, &v))
+	assert.Error(d.Fill(Tag{Value: "num=nil"}, &v))
+
+	assert.NoError(defaultDecoder.Fill(Tag{Value: "x,names='a',num=nil"}, &v))
+	assert.Equal(TestStruct{Name: "x", Names: []string{"a"}}, v)
+}
+
+func TestDecoder_IgnoreUnknownKeys(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Name string
+	}
+
+	var v TestStruct
+	assert.NoError(NewDecoder(IgnoreUnknownKeys()).Fill(Tag{Value: "x,other=1"}, &v))
+	assert.Equal("x", v.Name)
+	assert.Error(NewDecoder().Fill(Tag{Value: "x,other=1"}, &v))
+}
+
+func TestDecoder_Merge(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Names    []string
+		Ptr      *int
+		Priority int    `stragts:"default=10"`
+		Name     string `stragts:"required"`
+
+		Extra map[string]int `stragts:"remainder"`
+	}
+
+	layers := []string{
+		"names=a, ptr=1, name=x, priority=5, one=1",
+		"names=b;c, two=2",
+		"ptr=2",
+	}
+
+	d := NewDecoder(Merge())
+	var v TestStruct
+	for _, layer := range layers {
+		assert.NoError(d.Fill(Tag{Value: layer}, &v), "Fill(%v)", layer)
+	}
+	assert.Equal([]string{"a", "b", "c"}, v.Names)
+	assert.Equal(5, v.Priority)
+	assert.Equal("x", v.Name)
+	assert.Equal(map[string]int{"one": 1, "two": 2}, v.Extra)
+
+	ptr := v.Ptr
+	assert.NoError(d.Fill(Tag{Value: "ptr=3, names=nil"}, &v))
+	assert.Same(ptr, v.Ptr)
+	assert.Equal(3, *v.Ptr)
+	assert.Nil(v.Names)
+
+	// Zero values set by earlier fills count as set.
+	d = NewDecoder(Merge())
+	v = TestStruct{}
+	assert.NoError(d.Fill(Tag{Value: "priority=0, name=''"}, &v))
+	assert.NoError(d.Fill(Tag{Value: "names=a"}, &v))
+	assert.Equal(0, v.Priority)
+	assert.Equal("", v.Name)
+
+	// Every option struct is tracked on its own.
+	var w TestStruct
+	assert.Error(d.Fill(Tag{Value: "names=a"}, &w))
+
+	// Default values are replaced by later fills.
+	type Defaults struct {
+		Names []string `stragts:"default=a;b"`
+	}
+	var dv Defaults
+	d = NewDecoder(Merge())
+	assert.NoError(d.Fill(Tag{}, &dv))
+	assert.NoError(d.Fill(Tag{}, &dv))
+	assert.Equal([]string{"a", "b"}, dv.Names)
+	assert.NoError(d.Fill(Tag{Value: "names=c"}, &dv))
+	assert.NoError(d.Fill(Tag{Value: "names=d"}, &dv))
+	assert.Equal([]string{"c", "d"}, dv.Names)
+
+	// Without merging, every layer replaces the values of earlier ones.
+	v = TestStruct{}
+	assert.NoError(NewDecoder().Fill(Tag{Value: layers[0]}, &v))
+	ptr = v.Ptr
+	assert.Error(NewDecoder().Fill(Tag{Value: layers[1]}, &v))
+	assert.NoError(NewDecoder().Fill(Tag{Value: layers[1] + ", name=y"}, &v))
+	assert.Equal([]string{"b", "c"}, v.Names)
+	assert.Equal(10, v.Priority)
+	assert.Equal(map[string]int{"two": 2}, v.Extra)
+	assert.NoError(NewDecoder().Fill(Tag{Value: "ptr=2, name=y"}, &v))
+	assert.Same(ptr, v.Ptr)
+	assert.Equal(2, *v.Ptr)
+	assert.Nil(v.Extra)
+}
+
+func TestDecoder_DecodeHook(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Timeout  time.Duration
+		Timeouts []time.Duration
+		Name     string
+	}
+
+	var seen []Kind
+	d := NewDecoder(
+		WithDecodeHook(func(from Value, to reflect.Type) (any, error) {
+			seen = append(seen, from.Kind())
+			return from, nil
+		}),
+		WithDecodeHook(func(from Value, to reflect.Type) (any, error) {
+			if to != reflect.TypeOf(time.Duration(0)) {
+				return from, nil
+			}
+			s, _ := from.Text()
+			return time.ParseDuration(s)
+		}),
+	)
+
+	var v TestStruct
+	assert.NoError(d.Fill(Tag{Value: "timeout='1s',timeouts='1m';'1h',name=x"}, &v))
+	assert.Equal(TestStruct{Timeout: time.Second, Timeouts: []time.Duration{time.Minute, time.Hour}, Name: "x"}, v)
+	assert.Contains(seen, KindList)
+	assert.Contains(seen, KindIdentifier)
+
+	assert.Error(d.Fill(Tag{Value: "timeout='1y'"}, &v))
+}
+
+func TestDecoder_WithConverter(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type Level int
+
+	type TestStruct struct {
+		Timeout *time.Duration
+		Network net.IPNet
+		Addr    net.IP
+		Level   Level
+		Other   int
+	}
+
+	var calls []string
+	d := NewDecoder(
+		WithConverter(reflect.TypeOf(Level(0)), func(from Value, to reflect.Type) (any, error) {
+			calls = append(calls, "first")
+			return from, nil
+		}),
+		WithConverter(reflect.TypeOf(Level(0)), func(from Value, to reflect.Type) (any, error) {
+			calls = append(calls, "second")
+			if s, ok := from.Text(); ok && s == "high" {
+				return 10, nil
+			}
+			return from, nil
+		}),
+		WithConverter(reflect.TypeOf(time.Duration(0)), DurationSecondsHook),
+		WithDecodeHook(ComposeDecodeHooks(IPNetHook, TextUnmarshalerHook)),
+	)
+
+	var v TestStruct
+	assert.NoError(d.Fill(Tag{Value: "timeout=1.5,network='10.0.0.0/8',addr='127.0.0.1',level=high,other=3"}, &v))
+	if assert.NotNil(v.Timeout) {
+		assert.Equal(1500*time.Millisecond, *v.Timeout)
+	}
+	assert.Equal("10.0.0.0/8", v.Network.String())
+	assert.Equal(net.IPv4(127, 0, 0, 1).String(), v.Addr.String())
+	assert.Equal(Level(10), v.Level)
+	assert.Equal(3, v.Other)
+	assert.Equal([]string{"first", "second"}, calls)
+
+	assert.NoError(d.Fill(Tag{Value: "timeout='2m',level=2"}, &v))
+	assert.Equal(2*time.Minute, *v.Timeout)
+	assert.Equal(Level(2), v.Level)
+
+	assert.Error(d.Fill(Tag{Value: "network='10.0.0.0'"}, &v))
+	assert.Error(d.Fill(Tag{Value: "addr='localhost'"}, &v))
+	assert.Error(NewDecoder(WithConverter(reflect.TypeOf(0), func(Value, reflect.Type) (any, error) {
+		return "x", nil
+	})).Fill(Tag{Value: "other=1"}, &v))
+}
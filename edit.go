@@ -0,0 +1,156 @@
+package stragts
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SetKey sets the keyword argument key of the given tag value to the Go
+// value v and returns the edited tag value. The remaining text of the
+// tag, including quoting, spacing and argument order, is kept verbatim.
+// Existing occurrences of key are replaced in place, otherwise a new
+// keyword argument is appended. Values are spelled like Format spells
+// them, so boolean values are written as switches.
+func SetKey(tag, key string, v any) (string, error) {
+	if !isKey(key) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	value, err := formatValue(v)
+	if err != nil {
+		return "", err
+	}
+	arg := key + "=" + value
+	b, isBool := boolValue(v)
+	if isBool {
+		arg = string(switchPrefix(b)) + key
+	}
+
+	t, err := Parse(tag)
+	if err != nil {
+		return "", err
+	}
+
+	args := t.keywordArguments(key)
+	if len(args) == 0 {
+		return t.appendArgument(arg), nil
+	}
+
+	// Should the key be duplicated, change the last occurrence.
+	last := args[len(args)-1]
+	if isBool || last.isSwitch() {
+		// Switches are replaced as a whole.
+		return splice(t.text, last.pos, endPos(last), arg), nil
+	}
+	return splice(t.text, last.value.getPosition(), endPos(last.value), value), nil
+}
+
+// RenameKey renames the keyword argument oldKey of the given tag value
+// to newKey and returns the edited tag value. The remaining text of the
+// tag is kept verbatim.
+func RenameKey(tag, oldKey, newKey string) (string, error) {
+	if !isKey(newKey) {
+		return "", fmt.Errorf("invalid key %q", newKey)
+	}
+
+	t, err := Parse(tag)
+	if err != nil {
+		return "", err
+	}
+
+	args := t.keywordArguments(oldKey)
+	if len(args) == 0 {
+		return "", fmt.Errorf("key %q not found", oldKey)
+	}
+	if oldKey != newKey && len(t.keywordArguments(newKey)) > 0 {
+		return "", fmt.Errorf("key %q already exists", newKey)
+	}
+
+	// Work backwards so that earlier positions stay valid.
+	text := t.text
+	for i := len(args) - 1; i >= 0; i-- {
+		ident := args[i].ident
+		text = splice(text, ident.pos, endPos(ident), newKey)
+	}
+	return text, nil
+}
+
+// DeleteKey removes all occurrences of the keyword argument key from
+// the given tag value together with their separators and returns the
+// edited tag value. The remaining text of the tag is kept verbatim.
+func DeleteKey(tag, key string) (string, error) {
+	t, err := Parse(tag)
+	if err != nil {
+		return "", err
+	}
+
+	text := t.text
+	nodes := t.root.nodes
+	for i := len(nodes) - 1; i >= 0; i-- {
+		arg := nodes[i]
+		if arg.ident == nil || arg.ident.value != key {
+			continue
+		}
+
+		switch {
+		case i+1 < len(nodes):
+			// Take the following separator along.
+			text = splice(text, arg.pos, nodes[i+1].pos, "")
+		case i > 0:
+			// Last argument, take the preceding separator along.
+			text = splice(text, endPos(nodes[i-1]), endPos(arg), "")
+		default:
+			text = splice(text, arg.pos, endPos(arg), "")
+		}
+		nodes = append(nodes[:i:i], nodes[i+1:]...)
+	}
+	return text, nil
+}
+
+// keywordArguments returns all keyword arguments named key in order.
+func (t *tree) keywordArguments(key string) (out []*argumentNode) {
+	for _, n := range t.root.nodes {
+		if n.ident != nil && n.ident.value == key {
+			out = append(out, n)
+		}
+	}
+	return
+}
+
+// appendArgument returns the text of the tree with the given argument
+// appended, reusing the existing argument separator style.
+func (t *tree) appendArgument(arg string) string {
+	nodes := t.root.nodes
+	if len(nodes) == 0 {
+		return t.text + arg
+	}
+
+	separator := ","
+	if len(nodes) > 1 {
+		separator = t.text[endPos(nodes[0]):nodes[1].pos]
+	}
+	return splice(t.text, endPos(t.root), endPos(t.root), separator+arg)
+}
+
+// splice returns text with the span between start and end replaced by s.
+func splice(text string, start, end pos, s string) string {
+	return text[:start] + s + text[end:]
+}
+
+// isKey reports whether s can be used as the name of a keyword argument.
+// Dots separate the parts of a path to a nested option struct.
+func isKey(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		r, _ := utf8.DecodeRuneInString(part)
+		if !unicode.IsLetter(r) {
+			return false
+		}
+		for _, r := range part {
+			if r != '-' && !isAlphaNumeric(r) {
+				return false
+			}
+		}
+	}
+	return s != "true" && s != "false" && s != "nil"
+}
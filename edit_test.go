@@ -0,0 +1,125 @@
+package stragts
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetKey(t *testing.T) {
+	tests := []struct {
+		inp     string
+		key     string
+		value   any
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{inp: "", key: "priority", value: 3, want: "priority=3", wantErr: assert.NoError},
+		{inp: "idx", key: "priority", value: 3, want: "idx,priority=3", wantErr: assert.NoError},
+		{inp: "idx, unique=true", key: "priority", value: 3, want: "idx, unique=true, priority=3", wantErr: assert.NoError},
+		{inp: "idx , priority = 0x01 ,x=\"a\"", key: "priority", value: 3, want: "idx , priority = 3 ,x=\"a\"", wantErr: assert.NoError},
+		{inp: "priority=1,priority=2", key: "priority", value: 3, want: "priority=1,priority=3", wantErr: assert.NoError},
+
+		{inp: "!unique", key: "unique", value: true, want: "~unique", wantErr: assert.NoError},
+		{inp: "a, ~unique", key: "unique", value: "yes", want: "a, unique='yes'", wantErr: assert.NoError},
+		{inp: "a", key: "unique", value: true, want: "a,~unique", wantErr: assert.NoError},
+		{inp: "a, unique = true", key: "unique", value: false, want: "a, !unique", wantErr: assert.NoError},
+		{inp: "a", key: "flags", value: []bool{true, false}, want: "a,flags=true;false", wantErr: assert.NoError},
+
+		{inp: "a", key: "names", value: []string{"x", "it's"}, want: `a,names='x';"it's"`, wantErr: assert.NoError},
+		{inp: "a", key: "ptr", value: (*int)(nil), want: "a,ptr=nil", wantErr: assert.NoError},
+
+		{inp: "a", key: "db.name", value: "users", want: "a,db.name='users'", wantErr: assert.NoError},
+
+		{inp: "a", key: "1abc", value: 1, wantErr: assert.Error},
+		{inp: "a", key: "db..name", value: 1, wantErr: assert.Error},
+		{inp: "a", key: "nil", value: 1, wantErr: assert.Error},
+		{inp: "a", key: "b", value: map[string]int{}, wantErr: assert.Error},
+		{inp: "a", key: "b", value: [][]int{{1}}, wantErr: assert.Error},
+		{inp: "a", key: "names", value: []string{"x"}, wantErr: assert.Error},
+		{inp: "a,,", key: "b", value: 1, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.inp, func(t *testing.T) {
+			got, err := SetKey(tt.inp, tt.key, tt.value)
+			if !tt.wantErr(t, err, fmt.Sprintf("SetKey(%v, %v, %v)", tt.inp, tt.key, tt.value)) {
+				return
+			}
+			assert.Equalf(t, tt.want, got, "SetKey(%v, %v, %v)", tt.inp, tt.key, tt.value)
+		})
+	}
+}
+
+func TestSetKey_Canonical(t *testing.T) {
+	tag := ""
+	for _, kv := range []struct {
+		key   string
+		value any
+	}{
+		{"unique", true},
+		{"sparse", false},
+		{"priority", 0x10},
+		{"names", []string{"a", "b"}},
+		{"comment", "it's"},
+	} {
+		var err error
+		tag, err = SetKey(tag, kv.key, kv.value)
+		assert.NoError(t, err)
+	}
+
+	got, err := Format(tag)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tag, got)
+	}
+}
+
+func TestRenameKey(t *testing.T) {
+	tests := []struct {
+		inp     string
+		oldKey  string
+		newKey  string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{inp: "a, prio = 1", oldKey: "prio", newKey: "priority", want: "a, priority = 1", wantErr: assert.NoError},
+		{inp: "!uniq,uniq=true", oldKey: "uniq", newKey: "unique", want: "!unique,unique=true", wantErr: assert.NoError},
+
+		{inp: "a", oldKey: "prio", newKey: "priority", wantErr: assert.Error},
+		{inp: "a=1,b=2", oldKey: "a", newKey: "b", wantErr: assert.Error},
+		{inp: "a=1", oldKey: "a", newKey: "b c", wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.inp, func(t *testing.T) {
+			got, err := RenameKey(tt.inp, tt.oldKey, tt.newKey)
+			if !tt.wantErr(t, err, fmt.Sprintf("RenameKey(%v, %v, %v)", tt.inp, tt.oldKey, tt.newKey)) {
+				return
+			}
+			assert.Equalf(t, tt.want, got, "RenameKey(%v, %v, %v)", tt.inp, tt.oldKey, tt.newKey)
+		})
+	}
+}
+
+func TestDeleteKey(t *testing.T) {
+	tests := []struct {
+		inp  string
+		key  string
+		want string
+	}{
+		{inp: "", key: "a", want: ""},
+		{inp: "a=1", key: "a", want: ""},
+		{inp: "x, a=1, y", key: "a", want: "x, y"},
+		{inp: "x, y, a = 'z'", key: "a", want: "x, y"},
+		{inp: "a=1 , x , !a", key: "a", want: "x"},
+		{inp: "x,~a,a=2,a=3", key: "a", want: "x"},
+		{inp: "x,y=2", key: "a", want: "x,y=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.inp, func(t *testing.T) {
+			got, err := DeleteKey(tt.inp, tt.key)
+			if assert.NoError(t, err) {
+				assert.Equalf(t, tt.want, got, "DeleteKey(%v, %v)", tt.inp, tt.key)
+			}
+		})
+	}
+}
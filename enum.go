@@ -0,0 +1,75 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// enumRegistry holds the enum types registered with RegisterEnum.
+var enumRegistry sync.Map // map[reflect.Type]*enumType
+
+// enumType holds the members of an enum type by identifier.
+type enumType struct {
+	typ     reflect.Type
+	members map[string]any
+	names   string // sorted, comma separated member names for errors.
+}
+
+func newEnumType[T any](members map[string]T) *enumType {
+	e := &enumType{typ: reflect.TypeOf((*T)(nil)).Elem(), members: map[string]any{}}
+
+	names := make([]string, 0, len(members))
+	for name, v := range members {
+		e.members[name] = v
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	e.names = strings.Join(names, ", ")
+	return e
+}
+
+// hook decodes identifiers and strings naming a member of the enum.
+func (e *enumType) hook(from Value, to reflect.Type) (any, error) {
+	if to != e.typ || from.Kind() == KindNil {
+		return from, nil
+	}
+	if name, ok := from.Text(); ok {
+		if v, ok := e.members[name]; ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("unknown %s member %q, valid choices: %s", e.typ, name, e.names)
+	}
+	return nil, newKindError(ErrTypeMismatch, "cannot use %s as %s, valid choices: %s", from, e.typ, e.names)
+}
+
+// RegisterEnum registers the enum type T with the given members by
+// identifier for all decoders, so identifiers like "desc" in the tag
+// decode into the member constants of T and unknown identifiers are
+// rejected. Like gob.Register, it is meant to be called during
+// initialization, before T is decoded for the first time, and panics
+// if T has been registered before.
+func RegisterEnum[T any](members map[string]T) {
+	e := newEnumType(members)
+	if _, dup := enumRegistry.LoadOrStore(e.typ, e); dup {
+		panic("stragts: RegisterEnum called twice for type " + e.typ.String())
+	}
+}
+
+// WithEnum registers the enum type T with the given members by
+// identifier for a single decoder. See RegisterEnum.
+func WithEnum[T any](members map[string]T) Option {
+	e := newEnumType(members)
+	return WithConverter(e.typ, e.hook)
+}
+
+// registeredEnumHook returns the decode hook of the enum type t
+// registered with RegisterEnum, if any.
+func registeredEnumHook(t reflect.Type) DecodeHook {
+	if e, ok := enumRegistry.Load(t); ok {
+		return e.(*enumType).hook
+	}
+	return nil
+}
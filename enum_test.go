@@ -0,0 +1,73 @@
+package stragts
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type testOrder int
+
+const (
+	testOrderAsc testOrder = iota + 1
+	testOrderDesc
+)
+
+type testAction string
+
+const (
+	testCascade  testAction = "CASCADE"
+	testSetNull  testAction = "SET NULL"
+	testRestrict testAction = "RESTRICT"
+)
+
+func init() {
+	RegisterEnum(map[string]testOrder{"asc": testOrderAsc, "desc": testOrderDesc})
+}
+
+func TestRegisterEnum(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Order  testOrder
+		Orders []testOrder
+	}
+
+	var v TestStruct
+	assert.NoError(Tag{Value: "desc,orders=asc;'desc'"}.Fill(&v))
+	assert.Equal(TestStruct{Order: testOrderDesc, Orders: []testOrder{testOrderAsc, testOrderDesc}}, v)
+
+	err := Tag{Value: "order=up"}.Fill(&v)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), `unknown stragts.testOrder member "up", valid choices: asc, desc`)
+	}
+	assert.Error(Tag{Value: "order=1"}.Fill(&v))
+
+	assert.NoError(Tag{Value: "order=nil"}.Fill(&v))
+	assert.Equal(testOrder(0), v.Order)
+
+	assert.Panics(func() { RegisterEnum(map[string]testOrder{}) })
+}
+
+func TestWithEnum(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		OnDelete *testAction
+	}
+
+	d := NewDecoder(WithEnum(map[string]testAction{
+		"cascade":  testCascade,
+		"set-null": testSetNull,
+		"restrict": testRestrict,
+	}))
+
+	var v TestStruct
+	if assert.NoError(d.Fill(Tag{Value: "on-delete=set-null"}, &v)) {
+		assert.Equal(testSetNull, *v.OnDelete)
+	}
+	assert.Error(d.Fill(Tag{Value: "on-delete=drop"}, &v))
+
+	assert.NoError(Tag{Value: "on-delete=drop"}.Fill(&v))
+	assert.Equal(testAction("drop"), *v.OnDelete)
+}
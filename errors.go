@@ -0,0 +1,211 @@
+package stragts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Errors identifying the kind of a problem with a tag, for use with
+// errors.Is. They are returned wrapped into a SyntaxError or a
+// FieldError telling the location and the offending value.
+var (
+	ErrSyntax                 = errors.New("syntax error")
+	ErrPositionalAfterKeyword = errors.New("positional argument after keywords")
+	ErrDuplicateKey           = errors.New("duplicate key")
+	ErrUnknownKey             = errors.New("unknown key")
+	ErrTypeMismatch           = errors.New("type mismatch")
+	ErrOverflow               = errors.New("overflow")
+	ErrTooManyPositionals     = errors.New("too many positional arguments")
+)
+
+// ErrInvalidOptionStruct is the kind of errors about an option struct
+// that cannot be filled from any tag, such as a field with a broken
+// meta tag. These errors do not match any of the kinds above, even if
+// they are caused by a syntax error in a meta tag.
+var ErrInvalidOptionStruct = errors.New("invalid option struct")
+
+// kindError is an error of the kind of a sentinel error with a more
+// specific message.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func newKindError(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+// SyntaxError is a syntax error in a tag value. It matches ErrSyntax.
+type SyntaxError struct {
+	Pos int    // byte position of the error in the tag value.
+	End int    // byte position of the end of the offending text.
+	Msg string // description of the error.
+}
+
+func (e *SyntaxError) Error() string { return e.Msg }
+
+// Is reports whether target is ErrSyntax.
+func (e *SyntaxError) Is(target error) bool { return target == ErrSyntax }
+
+// ErrorList is a list of errors collected while processing several
+// tags or fields at once.
+type ErrorList []error
+
+// Error returns all error messages separated by newlines.
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+
+	var sb strings.Builder
+	for i, err := range l {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors of the list.
+func (l ErrorList) Unwrap() []error { return l }
+
+// Err returns an error equivalent to this error list.
+// If the list is empty, Err returns nil.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Is reports whether any error in the list matches target, for
+// errors.Is on Go versions not unwrapping multiple errors.
+func (l ErrorList) Is(target error) bool {
+	for _, err := range l {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in the list that matches target, for
+// errors.As on Go versions not unwrapping multiple errors.
+func (l ErrorList) As(target any) bool {
+	for _, err := range l {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldError is an error concerning a single argument of a tag, or a
+// single field of the option struct filled from it.
+type FieldError struct {
+	Field string // name of the field, if known.
+	Key   string // name of the keyword argument or key of the field, if any.
+	Arg   int    // index of the positional argument, -1 for keyword arguments and fields.
+	Pos   int    // byte position of the offending text in the tag value, -1 if none.
+	End   int    // byte position of the end of the offending text, -1 if none.
+	Value string // text of the value concerned, if any.
+	Err   error  // the underlying error.
+
+	prefix string // context preceding the message of Err.
+}
+
+// newFieldError returns a FieldError for err about the value n, which
+// may be nil.
+func newFieldError(n node, err error) *FieldError {
+	e := &FieldError{Arg: -1, Pos: -1, End: -1, Err: err}
+	if n != nil {
+		e.Pos, e.End, e.Value = int(n.getPosition()), int(endPos(n)), n.String()
+	}
+	return e
+}
+
+// at sets the offending text of e to the span of n.
+func (e *FieldError) at(n node) *FieldError {
+	e.Pos, e.End = int(n.getPosition()), int(endPos(n))
+	return e
+}
+
+func (e *FieldError) Error() string { return e.prefix + e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// FormatError renders err, as returned for the tag value tag, for
+// display. Every problem with a known location is shown as the tag
+// value followed by a line marking the offending text and the message:
+//
+//	index=a,,prio=1
+//	        ^ bad character U+002C ','
+//
+// Problems without a location are shown by their message only.
+func FormatError(tag string, err error) string {
+	if err == nil {
+		return ""
+	}
+	var list ErrorList
+	if !errors.As(err, &list) {
+		list = ErrorList{err}
+	}
+
+	var sb strings.Builder
+	for i, err := range list {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		start, end, ok := errorSpan(err)
+		if !ok || start > len(tag) {
+			sb.WriteString(err.Error())
+			continue
+		}
+
+		sb.WriteString(tag)
+		sb.WriteByte('\n')
+		// Keep tabs so the marker lines up with the text above.
+		for _, r := range tag[:start] {
+			if r == '\t' {
+				sb.WriteByte('\t')
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteByte('^')
+		if end > len(tag) {
+			end = len(tag)
+		}
+		if end > start {
+			_, w := utf8.DecodeRuneInString(tag[start:])
+			sb.WriteString(strings.Repeat("~", utf8.RuneCountInString(tag[start+w:end])))
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+// errorSpan returns the span of the offending text of err in the tag
+// value, if known.
+func errorSpan(err error) (start, end int, ok bool) {
+	var se *SyntaxError
+	if errors.As(err, &se) {
+		return se.Pos, se.End, true
+	}
+	var fe *FieldError
+	if errors.As(err, &fe) && fe.Pos >= 0 {
+		return fe.Pos, fe.End, true
+	}
+	return 0, 0, false
+}
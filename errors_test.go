@@ -0,0 +1,131 @@
+package stragts
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	type TestStruct struct {
+		Num  int8
+		Name string
+	}
+
+	tests := []struct {
+		inp   string
+		kind  error
+		pos   int
+		value string
+	}{
+		{inp: "1, name=x, name=y", kind: ErrDuplicateKey, pos: 11, value: "y"},
+		{inp: "name=x, 1", kind: ErrPositionalAfterKeyword, pos: 8, value: "1"},
+		{inp: "size=3", kind: ErrUnknownKey, pos: 0, value: "3"},
+		{inp: "num='x'", kind: ErrTypeMismatch, pos: 4, value: "'x'"},
+		{inp: "name=1;2", kind: ErrTypeMismatch, pos: 5, value: "1;2"},
+		{inp: "128", kind: ErrOverflow, pos: 0, value: "128"},
+		{inp: "1, x, y", kind: ErrTooManyPositionals, pos: 6, value: "y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.inp, func(t *testing.T) {
+			err := Tag{Value: tt.inp}.Fill(&TestStruct{})
+			assert.ErrorIs(t, err, tt.kind, fmt.Sprintf("Fill(%v)", tt.inp))
+
+			var fe *FieldError
+			if assert.ErrorAs(t, err, &fe, fmt.Sprintf("Fill(%v)", tt.inp)) {
+				assert.Equal(t, tt.pos, fe.Pos, "Fill(%v)", tt.inp)
+				assert.Equal(t, tt.value, fe.Value, "Fill(%v)", tt.inp)
+			}
+		})
+	}
+}
+
+func TestErrorKinds_OptionStruct(t *testing.T) {
+	type BadMeta struct {
+		Priority int `stragts:"default=1,,x"`
+	}
+	type BadDefault struct {
+		Priority int `stragts:"default='x'"`
+	}
+	type TwoRests struct {
+		A []string `stragts:"rest"`
+		B []string `stragts:"rest"`
+	}
+
+	for _, model := range []any{&BadMeta{}, &BadDefault{}, &TwoRests{}} {
+		err := Tag{Value: "priority=1"}.Fill(model)
+		assert.ErrorIs(t, err, ErrInvalidOptionStruct, "Fill(%T)", model)
+		assert.NotErrorIs(t, err, ErrSyntax, "Fill(%T)", model)
+		assert.NotErrorIs(t, err, ErrTypeMismatch, "Fill(%T)", model)
+	}
+
+	assert.NotErrorIs(t, Tag{Value: "a,,b"}.Fill(&BadMeta{}), ErrInvalidOptionStruct)
+}
+
+func TestSyntaxError(t *testing.T) {
+	for inp, pos := range map[string]int{
+		"a,,b":      2,
+		"a='b":      2,
+		"a=0x":      2,
+		"a = b c":   6,
+		"a=1;;2":    4,
+		"a=b,'\\q'": 4,
+	} {
+		_, err := Compile(inp)
+		assert.ErrorIs(t, err, ErrSyntax, "Compile(%v)", inp)
+
+		var se *SyntaxError
+		if assert.ErrorAs(t, err, &se, "Compile(%v)", inp) {
+			assert.Equal(t, pos, se.Pos, "Compile(%v)", inp)
+		}
+	}
+
+	assert.False(t, errors.Is(&SyntaxError{}, ErrUnknownKey))
+}
+
+func TestFormatError(t *testing.T) {
+	type TestStruct struct {
+		Index string
+		Prio  int8
+		Cols  []string `stragts:"required"`
+	}
+
+	tests := []struct {
+		inp  string
+		want string
+	}{
+		{inp: "index=a,,prio=1", want: "" +
+			"index=a,,prio=1\n" +
+			"        ^ bad character U+002C ','"},
+		{inp: "index='a", want: "" +
+			"index='a\n" +
+			"      ^~ unterminated quoted string"},
+		{inp: "a, prio=1000, size=3, cols=x", want: "" +
+			"a, prio=1000, size=3, cols=x\n" +
+			"        ^~~~ prio: 1000 overflows int8\n" +
+			"a, prio=1000, size=3, cols=x\n" +
+			`              ^~~~ unknown key "size"`},
+		{inp: "\tindex='äöü' ,  'x'", want: "" +
+			"\tindex='äöü' ,  'x'\n" +
+			"\t               ^~~ positional argument after keywords"},
+		{inp: "a", want: `missing required key "cols"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.inp, func(t *testing.T) {
+			err := Tag{Value: tt.inp}.Fill(&TestStruct{})
+			assert.Equal(t, tt.want, FormatError(tt.inp, err))
+		})
+	}
+
+	// Problems with the option struct are not located in the tag.
+	type BadMeta struct {
+		Priority int `stragts:"default=1,,x"`
+	}
+	err := Tag{Value: "priority=1"}.Fill(&BadMeta{})
+	assert.Equal(t, err.Error(), FormatError("priority=1", err))
+
+	assert.Equal(t, "", FormatError("a", nil))
+	assert.Equal(t, "boom", FormatError("a", errors.New("boom")))
+}
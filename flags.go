@@ -0,0 +1,137 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// integer is the set of types usable as bit flags.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// flagsRegistry holds the flag types registered with RegisterFlags.
+var flagsRegistry sync.Map // map[reflect.Type]*flagsType
+
+// flagsType holds the bits of a bit flag type by identifier.
+type flagsType struct {
+	typ   reflect.Type
+	bits  map[string]uint64
+	names string // sorted, comma separated flag names for errors.
+}
+
+func newFlagsType[T integer](flags map[string]T) *flagsType {
+	f := &flagsType{typ: reflect.TypeOf((*T)(nil)).Elem(), bits: map[string]uint64{}}
+
+	names := make([]string, 0, len(flags))
+	for name, bit := range flags {
+		f.bits[name] = uint64(bit)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	f.names = strings.Join(names, ", ")
+	return f
+}
+
+// decode decodes identifiers and switches naming flags into a bitmask.
+// Identifiers set their bits, "~name" sets and "!name" clears them. A
+// list consisting of switches only modifies the current value of v,
+// otherwise the value is replaced.
+func (f *flagsType) decode(v reflect.Value, n node) error {
+	var values []node
+	switch nv := n.(type) {
+	case *nilNode:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case *sliceNode:
+		values = nv.values
+	default:
+		values = []node{n}
+	}
+
+	var bits uint64
+	if v.CanInt() {
+		bits = uint64(v.Int())
+	} else {
+		bits = v.Uint()
+	}
+	for _, el := range values {
+		if el.getType() == nodeIdentifier {
+			bits = 0
+			break
+		}
+	}
+
+	for _, el := range values {
+		var (
+			name string
+			set  bool
+		)
+		switch ev := el.(type) {
+		case *identifierNode:
+			name, set = ev.value, true
+		case *switchNode:
+			name, set = ev.ident.value, ev.value.value
+		default:
+			return newKindError(ErrTypeMismatch, "cannot use %s as %s, valid flags: %s", el, f.typ, f.names)
+		}
+
+		bit, ok := f.bits[name]
+		if !ok {
+			return fmt.Errorf("unknown %s flag %q, valid flags: %s", f.typ, name, f.names)
+		}
+		if set {
+			bits |= bit
+		} else {
+			bits &^= bit
+		}
+	}
+
+	if v.CanInt() {
+		v.SetInt(int64(bits))
+	} else {
+		v.SetUint(bits)
+	}
+	return nil
+}
+
+// RegisterFlags registers the integer type T as a set of bit flags with
+// the given bits by identifier for all decoders, so a list of flag
+// names like "read;write" decodes into the bitwise or of their bits.
+// Switches in the list like "!write" clear the bits of a flag again.
+// Like gob.Register, it is meant to be called during initialization,
+// before T is decoded for the first time, and panics if T has been
+// registered before.
+func RegisterFlags[T integer](flags map[string]T) {
+	f := newFlagsType(flags)
+	if _, dup := flagsRegistry.LoadOrStore(f.typ, f); dup {
+		panic("stragts: RegisterFlags called twice for type " + f.typ.String())
+	}
+}
+
+// WithFlags registers the integer type T as a set of bit flags with the
+// given bits by identifier for a single decoder. See RegisterFlags.
+func WithFlags[T integer](flags map[string]T) Option {
+	f := newFlagsType(flags)
+	return func(d *Decoder) {
+		if d.flags == nil {
+			d.flags = map[reflect.Type]*flagsType{}
+		}
+		d.flags[f.typ] = f
+	}
+}
+
+// flagsFor returns the flag type registered for t, if any.
+func (d *Decoder) flagsFor(t reflect.Type) *flagsType {
+	if f, ok := d.flags[t]; ok {
+		return f
+	}
+	if f, ok := flagsRegistry.Load(t); ok {
+		return f.(*flagsType)
+	}
+	return nil
+}
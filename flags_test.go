@@ -0,0 +1,81 @@
+package stragts
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type testPerm uint8
+
+const (
+	testPermRead testPerm = 1 << iota
+	testPermWrite
+	testPermExec
+)
+
+func init() {
+	RegisterFlags(map[string]testPerm{
+		"read":  testPermRead,
+		"write": testPermWrite,
+		"exec":  testPermExec,
+		"all":   testPermRead | testPermWrite | testPermExec,
+	})
+}
+
+func TestRegisterFlags(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Perm  testPerm
+		Perms *testPerm
+	}
+
+	var v TestStruct
+	assert.NoError(Tag{Value: "perm=read;write;exec"}.Fill(&v))
+	assert.Equal(testPermRead|testPermWrite|testPermExec, v.Perm)
+
+	assert.NoError(Tag{Value: "perm=all;!write"}.Fill(&v))
+	assert.Equal(testPermRead|testPermExec, v.Perm)
+
+	// Switches alone modify the current value.
+	assert.NoError(Tag{Value: "perm=!exec;~write"}.Fill(&v))
+	assert.Equal(testPermRead|testPermWrite, v.Perm)
+	assert.NoError(Tag{Value: "perm=!read"}.Fill(&v))
+	assert.Equal(testPermWrite, v.Perm)
+
+	assert.NoError(Tag{Value: "exec,perms=read"}.Fill(&v))
+	assert.Equal(testPermExec, v.Perm)
+	if assert.NotNil(v.Perms) {
+		assert.Equal(testPermRead, *v.Perms)
+	}
+
+	assert.NoError(Tag{Value: "perm=nil"}.Fill(&v))
+	assert.Equal(testPerm(0), v.Perm)
+
+	err := Tag{Value: "perm=read;delete"}.Fill(&v)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), `unknown stragts.testPerm flag "delete", valid flags: all, exec, read, write`)
+	}
+	assert.Error(Tag{Value: "perm=1"}.Fill(&v))
+
+	assert.Panics(func() { RegisterFlags(map[string]testPerm{}) })
+}
+
+func TestWithFlags(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type Mode int
+
+	type TestStruct struct {
+		Mode Mode
+	}
+
+	d := NewDecoder(WithFlags(map[string]Mode{"a": 1, "b": 2, "c": 4}))
+
+	var v TestStruct
+	assert.NoError(d.Fill(Tag{Value: "a;c"}, &v))
+	assert.Equal(Mode(5), v.Mode)
+
+	assert.Error(Tag{Value: "a;c"}.Fill(&v))
+}
@@ -0,0 +1,216 @@
+package stragts
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FormatOption configures the output produced by Format.
+type FormatOption func(*formatter)
+
+// SortKeys orders keyword arguments by their name. Positional arguments
+// always keep their original order and precede all keyword arguments.
+func SortKeys() FormatOption {
+	return func(f *formatter) { f.sortKeys = true }
+}
+
+// Spaced separates arguments by a comma followed by a single space
+// instead of a bare comma.
+func Spaced() FormatOption {
+	return func(f *formatter) { f.separator = ", " }
+}
+
+// formatter holds the state of the canonical formatter.
+type formatter struct {
+	sortKeys  bool
+	separator string
+}
+
+// Format parses the given tag value and returns it in its canonical
+// form. Strings are quoted consistently, number literals are written
+// in their shortest decimal form, boolean keyword arguments are spelled
+// as switches and all insignificant whitespace is removed, so two tags
+// that decode identically are formatted identically.
+// Tags that Fill rejects for positional arguments following keyword
+// arguments or for repeated keys are rejected as well.
+func Format(tag string, opts ...FormatOption) (string, error) {
+	// A single dash is a conventional marker and not a parsable value.
+	if tag == "-" {
+		return tag, nil
+	}
+
+	// Reject tags that cannot be filled, reordering must not turn them
+	// into valid ones.
+	p, err := parseValue(tag)
+	if err != nil {
+		return "", err
+	}
+
+	f := formatter{separator: ","}
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	var sb strings.Builder
+	f.writeArguments(&sb, p)
+	return sb.String(), nil
+}
+
+func (f *formatter) writeArguments(sb *strings.Builder, p *parsed) {
+	keyword := p.keyword
+	if f.sortKeys {
+		keyword = append([]*argumentNode(nil), keyword...)
+		sort.Slice(keyword, func(i, j int) bool {
+			return keyword[i].ident.value < keyword[j].ident.value
+		})
+	}
+
+	nodes := append(p.indexed[:len(p.indexed):len(p.indexed)], keyword...)
+	for i, c := range nodes {
+		if i > 0 {
+			sb.WriteString(f.separator)
+		}
+		f.writeArgument(sb, c)
+	}
+}
+
+func (f *formatter) writeArgument(sb *strings.Builder, n *argumentNode) {
+	switch {
+	case n.ident == nil:
+		f.writeValue(sb, n.value)
+	case n.isSwitch():
+		n.value.writeTo(sb)
+	case n.value.getType() == nodeBool:
+		// Boolean keyword arguments are equivalent to switches.
+		sb.WriteByte(switchPrefix(n.value.(*boolNode).value))
+		n.ident.writeTo(sb)
+	default:
+		n.ident.writeTo(sb)
+		sb.WriteByte('=')
+		f.writeValue(sb, n.value)
+	}
+}
+
+func (f *formatter) writeValue(sb *strings.Builder, n node) {
+	switch nv := n.(type) {
+	case *numberNode:
+		sb.WriteString(formatNumber(nv))
+	case *stringNode:
+		sb.WriteString(quote(nv.Text))
+	case *sliceNode:
+		for i, c := range nv.values {
+			if i > 0 {
+				sb.WriteByte(';')
+			}
+			f.writeValue(sb, c)
+		}
+	default:
+		n.writeTo(sb)
+	}
+}
+
+// formatNumber returns the shortest decimal representation of n.
+func formatNumber(n *numberNode) string {
+	switch {
+	case n.IsInt:
+		return strconv.FormatInt(n.Int64, 10)
+	case n.IsUint:
+		return strconv.FormatUint(n.Uint64, 10)
+	default:
+		return strconv.FormatFloat(n.Float64, 'g', -1, 64)
+	}
+}
+
+// quote returns s as a quoted string literal. Single quotes are
+// preferred and double quotes are only used when s contains a single
+// quote itself.
+func quote(s string) string {
+	q := strconv.Quote(s)
+	if strings.ContainsRune(s, '\'') {
+		return q
+	}
+	return "'" + q[1:len(q)-1] + "'"
+}
+
+// switchPrefix returns the prefix of a switch setting a key to b.
+func switchPrefix(b bool) byte {
+	if b {
+		return '~'
+	}
+	return '!'
+}
+
+// boolValue returns the boolean held by v, if any, following pointers.
+func boolValue(v any) (b, ok bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Bool {
+		return false, false
+	}
+	return rv.Bool(), true
+}
+
+// formatValue returns the tag syntax representing the Go value v.
+func formatValue(v any) (string, error) {
+	var sb strings.Builder
+	if err := writeGoValue(&sb, reflect.ValueOf(v), true); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func writeGoValue(sb *strings.Builder, v reflect.Value, allowList bool) error {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Pointer, reflect.Interface:
+		sb.WriteString("nil")
+	case reflect.Bool:
+		sb.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sb.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sb.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("cannot represent %v in a tag", f)
+		}
+		sb.WriteString(strconv.FormatFloat(f, 'g', -1, v.Type().Bits()))
+	case reflect.String:
+		sb.WriteString(quote(v.String()))
+	case reflect.Slice, reflect.Array:
+		if !allowList {
+			return fmt.Errorf("cannot represent nested %s in a tag", v.Type())
+		}
+		if v.Len() == 0 {
+			return fmt.Errorf("cannot represent empty %s in a tag", v.Type())
+		}
+		if v.Len() == 1 {
+			// A list of one would read back as a single value.
+			return fmt.Errorf("cannot represent %s of length one in a tag", v.Type())
+		}
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sb.WriteByte(';')
+			}
+			if err := writeGoValue(sb, v.Index(i), false); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot represent %s in a tag", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package stragts
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		inp     string
+		opts    []FormatOption
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{inp: "", want: "", wantErr: assert.NoError},
+		{inp: "-", want: "-", wantErr: assert.NoError},
+		{inp: "foo , bar = baa", want: "foo,bar=baa", wantErr: assert.NoError},
+		{inp: "foo,bar=baa", opts: []FormatOption{Spaced()}, want: "foo, bar=baa", wantErr: assert.NoError},
+
+		{inp: `"hello world"`, want: "'hello world'", wantErr: assert.NoError},
+		{inp: `"it's"`, want: `"it's"`, wantErr: assert.NoError},
+		{inp: `'say \"hi\"'`, want: `'say \"hi\"'`, wantErr: assert.NoError},
+
+		{inp: "0x1F", want: "31", wantErr: assert.NoError},
+		{inp: "1_000", want: "1000", wantErr: assert.NoError},
+		{inp: "+5;-0", want: "5;0", wantErr: assert.NoError},
+		{inp: "1.50", want: "1.5", wantErr: assert.NoError},
+		{inp: "18446744073709551615", want: "18446744073709551615", wantErr: assert.NoError},
+
+		{inp: "foo=true,bar=false", want: "~foo,!bar", wantErr: assert.NoError},
+		{inp: "~foo,!bar", want: "~foo,!bar", wantErr: assert.NoError},
+		{inp: "perm = all ; !write", want: "perm=all;!write", wantErr: assert.NoError},
+		{inp: "db.name = users", want: "db.name=users", wantErr: assert.NoError},
+
+		{inp: "b,a,z=1,y=2", opts: []FormatOption{SortKeys()}, want: "b,a,y=2,z=1", wantErr: assert.NoError},
+		{inp: "z=1, ~b ,a='x';\"y\"", opts: []FormatOption{SortKeys(), Spaced()}, want: "a='x';'y', ~b, z=1", wantErr: assert.NoError},
+
+		{inp: "z=1,a,y=2,b", opts: []FormatOption{SortKeys()}, wantErr: assert.Error},
+		{inp: "b=1,a", wantErr: assert.Error},
+		{inp: "a=1,b,a=2", opts: []FormatOption{SortKeys()}, wantErr: assert.Error},
+		{inp: "db . name = x", wantErr: assert.Error},
+		{inp: "db..name=x", wantErr: assert.Error},
+		{inp: "db.=x", wantErr: assert.Error},
+		{inp: "foo,,bar", wantErr: assert.Error},
+		{inp: "foo bar", wantErr: assert.Error},
+		{inp: "foo='bar", wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.inp, func(t *testing.T) {
+			got, err := Format(tt.inp, tt.opts...)
+			if !tt.wantErr(t, err, fmt.Sprintf("Format(%v)", tt.inp)) {
+				return
+			}
+			assert.Equalf(t, tt.want, got, "Format(%v)", tt.inp)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package stragts
+
+import (
+	"encoding"
+	"fmt"
+	"net"
+	"reflect"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	ipNetType           = reflect.TypeOf(net.IPNet{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// ComposeDecodeHooks returns a decode hook running the given hooks in
+// order, each receiving the value returned by its predecessor, until
+// one of them returns a Go value.
+func ComposeDecodeHooks(hooks ...DecodeHook) DecodeHook {
+	return func(from Value, to reflect.Type) (any, error) {
+		for _, hook := range hooks {
+			out, err := hook(from, to)
+			if err != nil {
+				return nil, err
+			}
+			value, ok := out.(Value)
+			if !ok || value.n == nil {
+				return out, nil
+			}
+			from = value
+		}
+		return from, nil
+	}
+}
+
+// DurationSecondsHook decodes numbers into time.Duration values as
+// seconds and strings as understood by time.ParseDuration.
+func DurationSecondsHook(from Value, to reflect.Type) (any, error) {
+	if to != durationType {
+		return from, nil
+	}
+	if s, ok := from.Text(); ok {
+		return time.ParseDuration(s)
+	}
+	if f, ok := from.Float(); ok {
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	return from, nil
+}
+
+// IPNetHook decodes strings in CIDR notation into net.IPNet values.
+func IPNetHook(from Value, to reflect.Type) (any, error) {
+	if to != ipNetType {
+		return from, nil
+	}
+	s, ok := from.Text()
+	if !ok {
+		return from, nil
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	return *ipNet, nil
+}
+
+// TextUnmarshalerHook decodes strings and identifiers into values of
+// types implementing encoding.TextUnmarshaler.
+func TextUnmarshalerHook(from Value, to reflect.Type) (any, error) {
+	if to.Kind() == reflect.Pointer || !reflect.PointerTo(to).Implements(textUnmarshalerType) {
+		return from, nil
+	}
+	s, ok := from.Text()
+	if !ok {
+		return from, nil
+	}
+	v := reflect.New(to)
+	if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal %s into %s: %w", from, to, err)
+	}
+	return v.Elem().Interface(), nil
+}
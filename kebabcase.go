@@ -8,7 +8,9 @@ import (
 var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
 var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
 
-func toKebabCase(str string) string {
+// KebabCase converts a Go field name into its kebab-case form, like
+// "StringField" into "string-field".
+func KebabCase(str string) string {
 	kebab := matchFirstCap.ReplaceAllString(str, "${1}-${2}")
 	kebab = matchAllCap.ReplaceAllString(kebab, "${1}-${2}")
 	return strings.ToLower(kebab)
@@ -12,6 +12,7 @@ type item struct {
 	typ itemType // The type of this item.
 	pos pos      // The starting position, in bytes, of this item in the input string.
 	val string   // The simpleValue of this item.
+	end pos      // The end position of the span of error items.
 }
 
 func (i item) String() string {
@@ -92,10 +93,23 @@ func (l *lexer) undo() {
 
 // emit passes an item back to the client.
 func (l *lexer) emit(t itemType) {
-	l.items <- item{t, l.start, l.input[l.start:l.pos]}
+	l.items <- item{typ: t, pos: l.start, val: l.input[l.start:l.pos]}
 	l.start = l.pos
 }
 
+// ignore skips over the pending input before this point.
+func (l *lexer) ignore() {
+	l.start = l.pos
+}
+
+// skipSpace consumes and ignores a run of space characters.
+func (l *lexer) skipSpace() {
+	for isSpace(l.peek()) {
+		l.next()
+	}
+	l.ignore()
+}
+
 // accept consumes the next rune if it's from the valid set.
 func (l *lexer) accept(valid string) bool {
 	if strings.ContainsRune(valid, l.next()) {
@@ -115,16 +129,31 @@ func (l *lexer) acceptRun(valid string) {
 // errorf returns an error token and terminates the scan by passing
 // back a nil pointer that will be the next state, terminating l.item.
 func (l *lexer) errorf(format string, args ...any) stateFn {
-	l.items <- item{itemError, l.start, fmt.Sprintf(format, args...)}
+	l.items <- item{typ: itemError, pos: l.start, val: fmt.Sprintf(format, args...), end: l.pos}
 	return nil
 }
 
+// badCharacter returns an error token for the character r just
+// consumed, with the error spanning only that character.
+func (l *lexer) badCharacter(r rune) stateFn {
+	_, w := utf8.DecodeLastRuneInString(l.input[:l.pos])
+	l.start = l.pos - pos(w)
+	return l.errorf("bad character %#U", r)
+}
+
 // item returns the next item from the input.
 // Called by the parser, not in the lexing goroutine.
 func (l *lexer) item() item {
 	return <-l.items
 }
 
+// drain drains the output so the lexing goroutine will exit.
+// Called by the parser, not in the lexing goroutine.
+func (l *lexer) drain() {
+	for range l.items {
+	}
+}
+
 // atTerminator reports whether the input is at valid termination character to
 // appear after an identifier. Breaks .X.Y into two pieces. Also catches cases
 // like "$x+2" not being acceptable without a space, in case we decide one
@@ -150,20 +179,13 @@ func lex(input string) *lexer {
 
 // lexArgumentStart scans a single argument field.
 func lexArgumentStart(l *lexer) stateFn {
+	l.skipSpace()
 	switch r := l.next(); {
 	case r == eof:
 		l.emit(itemEOF)
 		return nil
 	case r == '~' || r == '!':
-		if !unicode.IsLetter(l.peek()) {
-			return l.errorf("bad character %#U", r)
-		}
-		if r == '!' {
-			l.emit(itemDisable)
-		} else {
-			l.emit(itemEnable)
-		}
-		return lexIdentifier
+		return lexSwitch(l, r)
 	case unicode.IsLetter(r):
 		l.undo()
 		return lexIdentifier
@@ -174,12 +196,26 @@ func lexArgumentStart(l *lexer) stateFn {
 		l.undo()
 		return lexQuote
 	default:
-		return l.errorf("bad character %#U", r)
+		return l.badCharacter(r)
+	}
+}
+
+// lexSwitch scans the identifier following the switch prefix r.
+func lexSwitch(l *lexer, r rune) stateFn {
+	if !unicode.IsLetter(l.peek()) {
+		return l.badCharacter(r)
+	}
+	if r == '!' {
+		l.emit(itemDisable)
+	} else {
+		l.emit(itemEnable)
 	}
+	return lexIdentifier
 }
 
 // lexInArgument scans a single argument field.
 func lexInArgument(l *lexer) stateFn {
+	l.skipSpace()
 	switch r := l.next(); {
 	case r == eof:
 		l.emit(itemEOF)
@@ -194,15 +230,18 @@ func lexInArgument(l *lexer) stateFn {
 		l.emit(itemAssign)
 		return lexValue
 	default:
-		return l.errorf("bad character %#U", r)
+		return l.badCharacter(r)
 	}
 }
 
-// lexValue scans a single string, integer, or identifier simpleValue.
+// lexValue scans a single string, integer, identifier, or switch simpleValue.
 func lexValue(l *lexer) stateFn {
+	l.skipSpace()
 	switch r := l.next(); {
 	case r == eof:
 		return l.errorf("assignment missing simpleValue")
+	case r == '~' || r == '!':
+		return lexSwitch(l, r)
 	case unicode.IsLetter(r):
 		l.undo()
 		return lexIdentifier
@@ -213,7 +252,7 @@ func lexValue(l *lexer) stateFn {
 		l.undo()
 		return lexQuote
 	default:
-		return l.errorf("bad character %#U", r)
+		return l.badCharacter(r)
 	}
 }
 
@@ -222,6 +261,11 @@ func lexIdentifier(l *lexer) stateFn {
 Loop:
 	for {
 		switch r := l.next(); {
+		case r == '.':
+			// Dots separate the parts of a path, each starting with a letter.
+			if !unicode.IsLetter(l.peek()) {
+				return l.badCharacter(r)
+			}
 		case r == '-':
 			fallthrough
 		case isAlphaNumeric(r):
@@ -230,7 +274,7 @@ Loop:
 			l.undo()
 			word := l.input[l.start:l.pos]
 			if !l.atTerminator() {
-				return l.errorf("bad character %#U", r)
+				return l.badCharacter(l.next())
 			}
 			switch word {
 			case "true", "false":
@@ -276,7 +320,7 @@ func lexNumber(l *lexer) stateFn {
 		return l.errorf("bad number syntax: %q", l.input[l.start:l.pos])
 	}
 	if !l.atTerminator() {
-		return l.errorf("bad character %#U", l.peek())
+		return l.badCharacter(l.next())
 	}
 	l.emit(itemNumber)
 	return lexInArgument
@@ -75,6 +75,7 @@ func Test_lex(t *testing.T) {
 
 		{"slice simpleValue", "foo;baa", []item{tIdentifier("foo"), tSliceSeparator, tIdentifier("baa"), tEOF}},
 		{"nil slice", "nil;nil", []item{tNil, tSliceSeparator, tNil, tEOF}},
+		{"switch slice", "foo;!baa", []item{tIdentifier("foo"), tSliceSeparator, tDisable, tIdentifier("baa"), tEOF}},
 
 		{"assign bool false", "foo=nil", []item{
 			tIdentifier("foo"), tAssign, tNil, tEOF,
@@ -91,6 +92,9 @@ func Test_lex(t *testing.T) {
 		{"assign identifier", "foo=baa", []item{
 			tIdentifier("foo"), tAssign, tIdentifier("baa"), tEOF,
 		}},
+		{"assign path", "db.name=users", []item{
+			tIdentifier("db.name"), tAssign, tIdentifier("users"), tEOF,
+		}},
 		{"assign quote", "foo='012baa'", []item{
 			tIdentifier("foo"), tAssign, tString("'012baa'"), tEOF,
 		}},
@@ -98,6 +102,9 @@ func Test_lex(t *testing.T) {
 		{"multiple#01", "one,two", []item{
 			tIdentifier("one"), tArgumentSeparator, tIdentifier("two"), tEOF,
 		}},
+		{"multiple#02", "one , two = 'three'", []item{
+			tIdentifier("one"), tArgumentSeparator, tIdentifier("two"), tAssign, tString("'three'"), tEOF,
+		}},
 		{"multiple#01", "one,two='three',foo=true", []item{
 			tIdentifier("one"), tArgumentSeparator, tIdentifier("two"), tAssign, tString("'three'"), tArgumentSeparator, tIdentifier("foo"), tAssign, tTrue, tEOF,
 		}},
@@ -0,0 +1,127 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MetaTagKey is the struct tag key under which fields of option structs
+// declare how they are filled, using the tag syntax itself:
+//
+//	type IndexOptions struct {
+//		Name     string `stragts:"required"`
+//		Priority int    `stragts:"default=10"`
+//	}
+//
+// The following field options are understood:
+//
+//	required        the tag must provide a value for the field.
+//	default=value   value used for the field if the tag provides none.
+//	min=number      lower bound of numbers, or of the length of strings,
+//	                slices and maps.
+//	max=number      upper bound of numbers, or of the length of strings,
+//	                slices and maps.
+//	len=number      exact length of strings, slices and maps.
+//	oneof=a;b;c     list of the values allowed for the field.
+//	pattern='re'    regular expression strings have to match.
+//	remainder       the field, a map with string keys, receives all
+//	                keyword arguments not matching any other field. It
+//	                cannot take any of the options above.
+//	rest            the field, a slice, receives all remaining positional
+//	                arguments. Later fields are keyword arguments only.
+//
+// The constraints are checked against the values provided by the tag,
+// default values are checked once when the option struct is first used.
+const MetaTagKey = "stragts"
+
+// fieldMeta holds the field options declared in the meta tag of a
+// field of an option struct.
+type fieldMeta struct {
+	required bool
+	def      node
+	min, max *numberNode
+	len      *numberNode
+	oneof    []node
+	pattern  string
+
+	remainder bool
+	rest      bool
+}
+
+// parseFieldMeta parses the meta tag of an option struct field.
+func parseFieldMeta(tag reflect.StructTag) (meta fieldMeta, err error) {
+	value, ok := tag.Lookup(MetaTagKey)
+	if !ok {
+		return meta, nil
+	}
+
+	t, err := Parse(value)
+	if err != nil {
+		// Keep the SyntaxError out of the chain, its position refers
+		// to the meta tag and not to the tag being filled.
+		return meta, fmt.Errorf("meta tag: %v", err)
+	}
+
+	for _, arg := range t.root.nodes {
+		name, value := "", arg.value
+		switch {
+		case arg.ident != nil:
+			name = arg.ident.value
+		case value.getType() == nodeIdentifier:
+			// A bare field option is a switch turning it on.
+			name, value = value.String(), t.newBool(value.getPosition(), true)
+		default:
+			return meta, fmt.Errorf("meta tag: unexpected %s", value)
+		}
+
+		switch name {
+		case "required":
+			meta.required, err = metaBool(name, value)
+		case "default":
+			meta.def = value
+		case "remainder":
+			meta.remainder, err = metaBool(name, value)
+		case "rest":
+			meta.rest, err = metaBool(name, value)
+		case "min":
+			meta.min, err = metaNumber(name, value)
+		case "max":
+			meta.max, err = metaNumber(name, value)
+		case "len":
+			meta.len, err = metaNumber(name, value)
+		case "oneof":
+			if sn, ok := value.(*sliceNode); ok {
+				meta.oneof = sn.values
+			} else {
+				meta.oneof = []node{value}
+			}
+		case "pattern":
+			var ok bool
+			if meta.pattern, ok = (Value{value}).Text(); !ok {
+				err = fmt.Errorf("meta tag: field option %q expects a string, not %s", name, value)
+			}
+		default:
+			err = fmt.Errorf("meta tag: unknown field option %q", name)
+		}
+		if err != nil {
+			return meta, err
+		}
+	}
+	return meta, nil
+}
+
+// metaBool returns the boolean value of the field option name.
+func metaBool(name string, n node) (bool, error) {
+	if b, ok := (Value{n}).Bool(); ok {
+		return b, nil
+	}
+	return false, fmt.Errorf("meta tag: field option %q expects a boolean, not %s", name, n)
+}
+
+// metaNumber returns the number value of the field option name.
+func metaNumber(name string, n node) (*numberNode, error) {
+	if nv, ok := n.(*numberNode); ok {
+		return nv, nil
+	}
+	return nil, fmt.Errorf("meta tag: field option %q expects a number, not %s", name, n)
+}
@@ -0,0 +1,194 @@
+package stragts
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestFieldMeta_RequiredAndDefault(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type IndexOptions struct {
+		Name     string   `stragts:"required"`
+		Priority int      `stragts:"default=10"`
+		Columns  []string `stragts:"default=id;'created at'"`
+		Unique   bool     `stragts:"required=false,default=true"`
+	}
+
+	v, err := Decode[IndexOptions](Tag{Value: "idx"})
+	if assert.NoError(err) {
+		assert.Equal(IndexOptions{Name: "idx", Priority: 10, Columns: []string{"id", "created at"}, Unique: true}, v)
+	}
+
+	v, err = Decode[IndexOptions](Tag{Value: "idx,priority=0,!unique,columns=nil"})
+	if assert.NoError(err) {
+		assert.Equal(IndexOptions{Name: "idx"}, v)
+	}
+
+	_, err = Decode[IndexOptions](Tag{Value: "priority=1"})
+	if assert.Error(err) {
+		assert.Equal(`missing required key "name"`, err.Error())
+	}
+
+	// Skipped tags are neither checked nor defaulted.
+	v, err = Decode[IndexOptions](Tag{Value: "-"})
+	if assert.NoError(err) {
+		assert.Equal(IndexOptions{}, v)
+	}
+}
+
+func TestFieldMeta_Errors(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type BadDefault struct {
+		Priority int `stragts:"default='high'"`
+	}
+	_, err := Decode[BadDefault](Tag{Value: ""})
+	assert.ErrorContains(err, "field Priority: bad default value")
+
+	type UnknownOption struct {
+		Priority int `stragts:"optional"`
+	}
+	_, err = Decode[UnknownOption](Tag{Value: ""})
+	assert.ErrorContains(err, `unknown field option "optional"`)
+
+	type RequiredDefault struct {
+		Priority int `stragts:"required,default=1"`
+	}
+	_, err = Decode[RequiredDefault](Tag{Value: ""})
+	assert.Error(err)
+
+	type BadRequired struct {
+		Priority int `stragts:"required=1"`
+	}
+	_, err = Decode[BadRequired](Tag{Value: ""})
+	assert.Error(err)
+
+	type BadSyntax struct {
+		Priority int `stragts:"default="`
+	}
+	_, err = Decode[BadSyntax](Tag{Value: ""})
+	assert.Error(err)
+}
+
+func TestFieldMeta_Remainder(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type PluginOptions struct {
+		Name  string
+		Extra map[string]Value `stragts:"remainder"`
+	}
+
+	var v PluginOptions
+	md, err := Tag{Value: "cache, ttl=30, ~compress, hosts='a';'b'"}.FillMetadata(&v)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("cache", v.Name)
+	assert.Len(v.Extra, 3)
+	if ttl, ok := v.Extra["ttl"].Int(); assert.True(ok) {
+		assert.Equal(int64(30), ttl)
+	}
+	if compress, ok := v.Extra["compress"].Bool(); assert.True(ok) {
+		assert.True(compress)
+	}
+	assert.Len(v.Extra["hosts"].List(), 2)
+	assert.Equal([]string{"Name", "Extra", "Extra", "Extra"}, []string{
+		md.Fields[0].Field, md.Fields[1].Field, md.Fields[2].Field, md.Fields[3].Field,
+	})
+
+	// The remainder is replaced by every tag.
+	assert.NoError(Tag{Value: "name=x,ttl=60"}.Fill(&v))
+	assert.Len(v.Extra, 1)
+	assert.NoError(Tag{Value: "name=y"}.Fill(&v))
+	assert.Nil(v.Extra)
+
+	// Remainders can have any element type.
+	type TypedOptions struct {
+		Extra map[string]int `stragts:"remainder"`
+	}
+	var typed TypedOptions
+	assert.NoError(Tag{Value: "a=1,b=2"}.Fill(&typed))
+	assert.Equal(map[string]int{"a": 1, "b": 2}, typed.Extra)
+	assert.Error(Tag{Value: "a='x'"}.Fill(&typed))
+
+	type BadRemainder struct {
+		Extra []string `stragts:"remainder"`
+	}
+	_, err = Decode[BadRemainder](Tag{Value: "a=1"})
+	assert.ErrorContains(err, "remainder field must be a map with string keys")
+
+	type TwoRemainders struct {
+		A map[string]Value `stragts:"remainder"`
+		B map[string]Value `stragts:"remainder"`
+	}
+	_, err = Decode[TwoRemainders](Tag{Value: "a=1"})
+	assert.ErrorContains(err, "fields A and B are both remainders")
+
+	for _, model := range []any{
+		&struct {
+			Extra map[string]int `stragts:"remainder,required"`
+		}{},
+		&struct {
+			Extra map[string]int `stragts:"remainder,default=1"`
+		}{},
+		&struct {
+			Extra map[string]int `stragts:"remainder,max=2"`
+		}{},
+	} {
+		err := Tag{Value: "a=1"}.Fill(model)
+		assert.ErrorContains(err, "remainder field cannot be required, have a default value or constraints")
+	}
+}
+
+func TestFieldMeta_Rest(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type IndexOptions struct {
+		Name    string
+		Columns []string `stragts:"rest,min=1"`
+		Unique  bool
+	}
+
+	var v IndexOptions
+	md, err := Tag{Value: "idx,a,b,c,unique=true"}.FillMetadata(&v)
+	if assert.NoError(err) {
+		assert.Equal(IndexOptions{Name: "idx", Columns: []string{"a", "b", "c"}, Unique: true}, v)
+		if src, ok := md.Lookup("Columns"); assert.True(ok) {
+			assert.Equal(1, src.Arg)
+			assert.Equal(4, src.Pos)
+		}
+		assert.Len(md.Fields, 5)
+	}
+
+	// The rest field can still be set by keyword.
+	v = IndexOptions{}
+	assert.NoError(Tag{Value: "idx,columns=a;b"}.Fill(&v))
+	assert.Equal([]string{"a", "b"}, v.Columns)
+
+	_, err = Decode[IndexOptions](Tag{Value: "idx,a,1"})
+	assert.EqualError(err, "arguments #1 and following: element #1: cannot use 1 as string")
+
+	_, err = Decode[IndexOptions](Tag{Value: "idx,columns=nil"})
+	assert.EqualError(err, `key "columns" at position 12: nil is less than min=1`)
+
+	type NoRest struct {
+		Name string
+	}
+	_, err = Decode[NoRest](Tag{Value: "a,b"})
+	assert.EqualError(err, "too many positional arguments: 2 given, 1 accepted")
+
+	type BadRest struct {
+		Columns string `stragts:"rest"`
+	}
+	_, err = Decode[BadRest](Tag{Value: "a,b"})
+	assert.ErrorContains(err, "rest field must be a slice")
+
+	type TwoRests struct {
+		A []string `stragts:"rest"`
+		B []string `stragts:"rest"`
+	}
+	_, err = Decode[TwoRests](Tag{Value: "a,b"})
+	assert.ErrorContains(err, "fields A and B both take the rest")
+}
@@ -0,0 +1,47 @@
+package stragts
+
+// Metadata describes which fields of an option struct have been set
+// by the arguments of a tag.
+type Metadata struct {
+	// Fields lists the fields set by arguments of the tag in the order
+	// of the arguments. Fields set from default values are not listed.
+	Fields []FieldSource
+
+	// Unused lists the keyword arguments of the tag that did not match
+	// any field and have been ignored.
+	Unused []string
+}
+
+// FieldSource describes the argument a field has been set from.
+type FieldSource struct {
+	Field string // name of the field, qualified by the names of embedded and nested structs.
+	Index []int  // index sequence of the field.
+	Key   string // name of the keyword argument, empty for positional arguments.
+	Arg   int    // index of the positional argument, -1 for keyword arguments.
+	Pos   int    // byte position of the argument in the tag value.
+}
+
+// IsSet reports whether the field with the given name has been set by
+// an argument of the tag.
+func (md *Metadata) IsSet(field string) bool {
+	_, ok := md.Lookup(field)
+	return ok
+}
+
+// Lookup returns the source of the field with the given name.
+func (md *Metadata) Lookup(field string) (src FieldSource, ok bool) {
+	for _, src := range md.Fields {
+		if src.Field == field {
+			return src, true
+		}
+	}
+	return
+}
+
+func (md *Metadata) add(fp *fieldPlan, arg *argumentNode, index int) {
+	src := FieldSource{Field: fp.name, Index: fp.index, Arg: index, Pos: int(arg.pos)}
+	if arg.ident != nil {
+		src.Key = arg.ident.value
+	}
+	md.Fields = append(md.Fields, src)
+}
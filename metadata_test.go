@@ -0,0 +1,47 @@
+package stragts
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestTag_FillMetadata(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TestStruct struct {
+		Index    string
+		Priority int `stragts:"default=5"`
+		Unique   bool
+	}
+
+	var v TestStruct
+	md, err := Tag{Value: "idx, !unique"}.FillMetadata(&v)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([]FieldSource{
+		{Field: "Index", Index: []int{0}, Arg: 0, Pos: 0},
+		{Field: "Unique", Index: []int{2}, Key: "unique", Arg: -1, Pos: 5},
+	}, md.Fields)
+	assert.Empty(md.Unused)
+
+	assert.True(md.IsSet("Index"))
+	assert.False(md.IsSet("Priority"))
+	assert.Equal(5, v.Priority)
+
+	src, ok := md.Lookup("Unique")
+	if assert.True(ok) {
+		assert.Equal(5, src.Pos)
+	}
+
+	md, err = NewDecoder(IgnoreUnknownKeys()).FillMetadata(Tag{Value: "priority=0,other=1,more=x"}, &v)
+	if assert.NoError(err) {
+		assert.True(md.IsSet("Priority"))
+		assert.Equal(0, v.Priority)
+		assert.Equal([]string{"other", "more"}, md.Unused)
+	}
+
+	_, err = Tag{Value: "other=1"}.FillMetadata(&v)
+	assert.Error(err)
+}
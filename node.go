@@ -14,6 +14,24 @@ func (p pos) getPosition() pos {
 	return p
 }
 
+// endPos returns the position just after the last byte of n in the input text.
+func endPos(n node) pos {
+	switch nv := n.(type) {
+	case *listNode:
+		if len(nv.nodes) == 0 {
+			return nv.pos
+		}
+		return endPos(nv.nodes[len(nv.nodes)-1])
+	case *argumentNode:
+		return endPos(nv.value)
+	case *sliceNode:
+		return endPos(nv.values[len(nv.values)-1])
+	case *switchNode:
+		return endPos(nv.ident)
+	}
+	return n.getPosition() + pos(len(n.String()))
+}
+
 type node interface {
 	getType() nodeType
 
@@ -95,13 +113,19 @@ func (n *argumentNode) String() string {
 }
 
 func (n *argumentNode) writeTo(sb *strings.Builder) {
-	if n.ident != nil && n.value.getType() != nodeSwitch {
+	if n.ident != nil && !n.isSwitch() {
 		sb.WriteString(n.ident.String())
 		sb.WriteByte('=')
 	}
 	sb.WriteString(n.value.String())
 }
 
+// isSwitch reports whether the argument is a switch like "~foo".
+func (n *argumentNode) isSwitch() bool {
+	sw, ok := n.value.(*switchNode)
+	return ok && sw.argument
+}
+
 func (t *tree) newArgument(pos pos, ident *identifierNode, value node) *argumentNode {
 	return &argumentNode{baseNode: newBaseNode(nodeArgument, pos), ident: ident, value: value}
 }
@@ -193,8 +217,9 @@ func (t *tree) newSlice(pos pos, values []node) *sliceNode {
 // switchNode holds an enabling or disabling field value.
 type switchNode struct {
 	baseNode
-	ident *identifierNode
-	value *boolNode
+	ident    *identifierNode
+	value    *boolNode
+	argument bool // whether the switch is an argument of its own, like "~foo".
 }
 
 func (n *switchNode) String() string {
@@ -1,16 +1,8 @@
 package stragts
 
-import (
-	"errors"
-)
-
-var (
-	ErrPositionalAfterKeyword = errors.New("positional argument after keywords")
-)
-
 type parsed struct {
-	indexed []node
-	keyword map[string]node
+	indexed []*argumentNode
+	keyword []*argumentNode // in order.
 }
 
 func parseValue(inp string) (*parsed, error) {
@@ -18,18 +10,37 @@ func parseValue(inp string) (*parsed, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newParsed(t)
+}
 
-	p := &parsed{keyword: map[string]node{}}
-	for _, n := range t.root.nodes {
-		if n.ident == nil {
+// newParsed splits the arguments of the parsed tag value t into
+// positional and keyword arguments. Positional arguments following
+// keyword arguments and repeated keys are reported as errors.
+func newParsed(t *tree) (*parsed, error) {
+	p := &parsed{}
+	var errs ErrorList
+	seen := map[string]bool{}
+	for i, n := range t.root.nodes {
+		switch {
+		case n.ident == nil:
 			if len(p.keyword) != 0 {
-				return nil, ErrPositionalAfterKeyword
+				e := newFieldError(n.value, ErrPositionalAfterKeyword)
+				e.Arg = i
+				errs = append(errs, e)
+				continue
 			}
-			p.indexed = append(p.indexed, n.value)
-		} else {
-			p.keyword[n.ident.String()] = n.value
+			p.indexed = append(p.indexed, n)
+		case seen[n.ident.value]:
+			e := newFieldError(n.value, newKindError(ErrDuplicateKey, "duplicate key %q", n.ident.value)).at(n.ident)
+			e.Key = n.ident.value
+			errs = append(errs, e)
+		default:
+			seen[n.ident.value] = true
+			p.keyword = append(p.keyword, n)
 		}
 	}
-
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
 	return p, nil
 }
@@ -0,0 +1,606 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldPlan describes how to decode a value into a single field of
+// an option struct.
+type fieldPlan struct {
+	id       int        // index of the field within the plan.
+	name     string     // name of the field.
+	key      string     // keyword argument name of the field, if any.
+	index    []int      // index sequence of the field.
+	decode   decodeFunc // decoder for the type of the field.
+	required bool       // whether the tag must provide the field.
+	def      node       // default value of the field, if any.
+
+	constraints []constraint       // constraints on values provided by the tag.
+	elem        decodeFunc         // decoder for the map elements of a remainder field.
+	rest        bool               // whether the field receives the remaining positional arguments.
+	nested      func() *structPlan // plan of the nested option struct addressed by dotted keys, if any.
+}
+
+// set decodes n into the field of the option struct m.
+func (fp *fieldPlan) set(m reflect.Value, n node) error {
+	f, err := fieldByIndex(m, fp.index)
+	if err != nil {
+		return err
+	}
+	if !f.CanSet() {
+		return fmt.Errorf("cannot set unexported field %s", fp.name)
+	}
+	return fp.decode(f, n)
+}
+
+// check checks the value of the field of the option struct m against
+// the constraints of the field and returns the violation, if any.
+func (fp *fieldPlan) check(m reflect.Value) string {
+	if len(fp.constraints) == 0 {
+		return ""
+	}
+	f, err := fieldByIndex(m, fp.index)
+	if err != nil {
+		return err.Error()
+	}
+	return checkConstraints(fp.constraints, f)
+}
+
+// setEntry decodes n into the entry key of the map in the remainder
+// field of the option struct m, allocating the map if needed.
+func (fp *fieldPlan) setEntry(m reflect.Value, key string, n node) error {
+	f, err := fieldByIndex(m, fp.index)
+	if err != nil {
+		return err
+	}
+	if !f.CanSet() {
+		return fmt.Errorf("cannot set unexported field %s", fp.name)
+	}
+	if f.IsNil() {
+		f.Set(reflect.MakeMap(f.Type()))
+	}
+
+	ev := reflect.New(f.Type().Elem()).Elem()
+	if err := fp.elem(ev, n); err != nil {
+		return err
+	}
+	f.SetMapIndex(reflect.ValueOf(key).Convert(f.Type().Key()), ev)
+	return nil
+}
+
+// clear sets the field of the option struct m to its zero value.
+func (fp *fieldPlan) clear(m reflect.Value) error {
+	f, err := fieldByIndex(m, fp.index)
+	if err != nil {
+		return err
+	}
+	if !f.CanSet() {
+		return fmt.Errorf("cannot set unexported field %s", fp.name)
+	}
+	f.Set(reflect.Zero(f.Type()))
+	return nil
+}
+
+// describe returns how the field is referred to in error messages.
+func (fp *fieldPlan) describe() string {
+	if fp.key != "" {
+		return fmt.Sprintf("key %q", fp.key)
+	}
+	return "field " + fp.name
+}
+
+// structPlan is the compiled decode plan of an option struct type.
+type structPlan struct {
+	err           error                 // error building the plan, if any.
+	numFields     int                   // number of fields in the plan.
+	positional    []*fieldPlan          // fields by positional argument index.
+	rest          *fieldPlan            // field receiving the remaining positional arguments, if any.
+	keyword       map[string]*fieldPlan // fields by keyword argument name.
+	ambiguous     map[string]string     // fields sharing an ambiguous keyword argument name.
+	remainder     *fieldPlan            // field receiving unknown keywords, if any.
+	checked       []*fieldPlan          // fields that are required, have defaults or constraints.
+	validate      bool                  // whether the option struct is a Validator.
+	ignoreUnknown bool                  // whether unknown keywords are ignored.
+	merge         bool                  // whether values are merged into earlier fills.
+	presence      *sync.Map             // map[any][]presence of the fields set by earlier fills, when merging.
+}
+
+// presence tells how an earlier fill merged into has set a field.
+type presence uint8
+
+const (
+	unset        presence = iota
+	setByTag              // set by an argument of the tag.
+	setByDefault          // set to the default value of the field.
+)
+
+// fill decodes the parsed tag p into the option struct m, recording
+// the source of every field set in md if it is not nil. All problems
+// found are returned together as an ErrorList of *FieldError.
+func (sp *structPlan) fill(m reflect.Value, p *parsed, md *Metadata) error {
+	if sp.err != nil {
+		return sp.err
+	}
+
+	// Keep track of the values of fields set by the tag only when needed.
+	var set []node
+	if len(sp.checked) > 0 {
+		set = make([]node, sp.numFields)
+	}
+	var errs ErrorList
+
+	// When merging, the fields set by earlier fills of the same option
+	// struct count as set, even if they hold the zero value.
+	var earlier []presence
+	if sp.merge && set != nil {
+		key := m.Addr().Interface()
+		if v, ok := sp.presence.Load(key); ok {
+			earlier = v.([]presence)
+		} else {
+			earlier = make([]presence, sp.numFields)
+			sp.presence.Store(key, earlier)
+		}
+	}
+
+	for i, arg := range p.indexed {
+		if i >= len(sp.positional) {
+			if sp.rest != nil {
+				replaceDefault(m, sp.rest, earlier)
+				if err := sp.fillRest(m, p.indexed[i:], i, set, md); err != nil {
+					errs = append(errs, err)
+				}
+				break
+			}
+			e := newFieldError(arg.value, newKindError(ErrTooManyPositionals,
+				"too many positional arguments: %d given, %d accepted", len(p.indexed), len(sp.positional)))
+			e.Arg = i
+			errs = append(errs, e)
+			break
+		}
+		fp := sp.positional[i]
+		replaceDefault(m, fp, earlier)
+		if err := fp.set(m, arg.value); err != nil {
+			errs = append(errs, argumentError(fp, arg, i, err))
+			markSet(set, fp, failed)
+			continue
+		}
+		markSet(set, fp, arg.value)
+		if md != nil {
+			md.add(fp, arg, i)
+		}
+	}
+
+	if sp.remainder != nil && !sp.merge {
+		// Unknown keys of earlier fills are not kept.
+		if err := sp.remainder.clear(m); err != nil {
+			errs = append(errs, sp.remainder.error(nil, err))
+		}
+	}
+
+	var nested []nestedArguments
+	for _, arg := range p.keyword {
+		k := arg.ident.value
+		fp, ok := sp.keyword[k]
+		if !ok {
+			if head, tail, found := strings.Cut(k, "."); found {
+				if fp, ok := sp.keyword[head]; ok && fp.nested != nil {
+					nested = addNested(nested, fp, arg, tail)
+					continue
+				}
+			}
+			if fields, ok := sp.ambiguous[k]; ok {
+				errs = append(errs, keyError(arg, fmt.Errorf("ambiguous key %q: matches fields %s", k, fields)))
+				continue
+			}
+			if sp.remainder != nil {
+				if err := sp.remainder.setEntry(m, k, arg.value); err != nil {
+					errs = append(errs, argumentError(sp.remainder, arg, -1, err))
+					continue
+				}
+				if md != nil {
+					md.add(sp.remainder, arg, -1)
+				}
+				continue
+			}
+			if sp.ignoreUnknown {
+				if md != nil {
+					md.Unused = append(md.Unused, k)
+				}
+				continue
+			}
+			errs = append(errs, keyError(arg, newKindError(ErrUnknownKey, "unknown key %q", k)))
+			continue
+		}
+		replaceDefault(m, fp, earlier)
+		if err := fp.set(m, arg.value); err != nil {
+			errs = append(errs, argumentError(fp, arg, -1, err))
+			markSet(set, fp, failed)
+			continue
+		}
+		markSet(set, fp, arg.value)
+		if md != nil {
+			md.add(fp, arg, -1)
+		}
+	}
+
+	for _, na := range nested {
+		if err := na.fill(m, md); err != nil {
+			errs = append(errs, na.errors(err)...)
+			markSet(set, na.fp, failed)
+			continue
+		}
+		markSet(set, na.fp, na.p.keyword[0].value)
+	}
+
+	for _, fp := range sp.checked {
+		switch n := set[fp.id]; {
+		case n == failed:
+			// Reported already.
+		case n != nil:
+			if msg := fp.check(m); msg != "" {
+				errs = append(errs, fp.error(n, fmt.Errorf("%s at position %d: %s %s", fp.describe(), n.getPosition(), n, msg)))
+			}
+		case earlier != nil && earlier[fp.id] != unset:
+			// Keep the value of an earlier fill.
+		case fp.required:
+			errs = append(errs, fp.error(nil, fmt.Errorf("missing required %s", fp.describe())))
+		case fp.def != nil:
+			if err := fp.set(m, fp.def); err != nil {
+				errs = append(errs, fp.error(nil, fmt.Errorf("default of %s: %w", fp.describe(), err)))
+			} else if earlier != nil {
+				earlier[fp.id] = setByDefault
+			}
+		}
+		if n := set[fp.id]; n != nil && n != failed && earlier != nil {
+			earlier[fp.id] = setByTag
+		}
+	}
+
+	if sp.validate && len(errs) == 0 {
+		return m.Addr().Interface().(Validator).Validate()
+	}
+	return errs.Err()
+}
+
+// replaceDefault clears the field fp of the option struct m if an
+// earlier fill has set it to its default value, so values of the tag
+// replace the default instead of being merged into it.
+func replaceDefault(m reflect.Value, fp *fieldPlan, earlier []presence) {
+	if earlier != nil && earlier[fp.id] == setByDefault {
+		// Errors show up again when setting the field.
+		_ = fp.clear(m)
+	}
+}
+
+// failed marks fields that failed to decode in the list of set fields.
+var failed node = &nilNode{}
+
+// markSet records n as the value of the field fp in the list of set
+// fields, if it is kept.
+func markSet(set []node, fp *fieldPlan, n node) {
+	if set != nil {
+		set[fp.id] = n
+	}
+}
+
+// argumentError returns the error for the argument arg at the given
+// positional index, or -1 for keyword arguments, failing to decode
+// into the field fp.
+func argumentError(fp *fieldPlan, arg *argumentNode, index int, err error) *FieldError {
+	e := newFieldError(arg.value, err)
+	e.Field, e.Arg = fp.name, index
+	if index >= 0 {
+		e.Key = fp.key
+		e.prefix = fmt.Sprintf("argument #%d: ", index)
+	} else {
+		e.Key = arg.ident.value
+		e.prefix = e.Key + ": "
+	}
+	return e
+}
+
+// keyError returns the error for the key of the keyword argument arg
+// not matching a field.
+func keyError(arg *argumentNode, err error) *FieldError {
+	e := newFieldError(arg.value, err).at(arg.ident)
+	e.Key = arg.ident.value
+	return e
+}
+
+// error returns the error for the field fp set from n, if any.
+func (fp *fieldPlan) error(n node, err error) *FieldError {
+	e := newFieldError(n, err)
+	e.Field, e.Key = fp.name, fp.key
+	return e
+}
+
+// fillRest decodes the remaining positional arguments args, starting
+// at the index offset, as a list into the rest field.
+func (sp *structPlan) fillRest(m reflect.Value, args []*argumentNode, offset int, set []node, md *Metadata) error {
+	values := make([]node, len(args))
+	for i, arg := range args {
+		values[i] = arg.value
+	}
+	n := &sliceNode{baseNode: newBaseNode(nodeSlice, args[0].pos), values: values}
+
+	fp := sp.rest
+	if err := fp.set(m, n); err != nil {
+		markSet(set, fp, failed)
+		e := fp.error(n, err)
+		e.Arg = offset
+		e.prefix = fmt.Sprintf("arguments #%d and following: ", offset)
+		return e
+	}
+	markSet(set, fp, n)
+	if md != nil {
+		for i, arg := range args {
+			md.add(fp, arg, offset+i)
+		}
+	}
+	return nil
+}
+
+// nestedArguments holds the keyword arguments addressing the nested
+// option struct of a field by dotted keys, with the field key removed.
+type nestedArguments struct {
+	fp *fieldPlan
+	p  parsed
+}
+
+// addNested adds the keyword argument arg, addressing the key path in
+// the nested option struct of fp, to the list of nested arguments.
+func addNested(nested []nestedArguments, fp *fieldPlan, arg *argumentNode, path string) []nestedArguments {
+	ident := arg.ident
+	sub := &argumentNode{
+		baseNode: arg.baseNode,
+		ident: &identifierNode{
+			baseNode: newBaseNode(nodeIdentifier, ident.pos+pos(len(ident.value)-len(path))),
+			value:    path,
+		},
+		value: arg.value,
+	}
+
+	for i := range nested {
+		if nested[i].fp == fp {
+			nested[i].p.keyword = append(nested[i].p.keyword, sub)
+			return nested
+		}
+	}
+	return append(nested, nestedArguments{fp: fp, p: parsed{keyword: []*argumentNode{sub}}})
+}
+
+// fill fills the nested option struct in the field of the option struct
+// m, allocating it if the field is a nil pointer. The nested option
+// struct is filled as if the arguments were a tag of its own.
+func (na *nestedArguments) fill(m reflect.Value, md *Metadata) error {
+	f, err := fieldByIndex(m, na.fp.index)
+	if err != nil {
+		return err
+	}
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		f = f.Elem()
+	}
+
+	var nmd *Metadata
+	if md != nil {
+		nmd = &Metadata{}
+	}
+	if err := na.fp.nested().fill(f, &na.p, nmd); err != nil {
+		return err
+	}
+	if md != nil {
+		for _, src := range nmd.Fields {
+			src.Field = na.fp.name + "." + src.Field
+			src.Key = na.fp.key + "." + src.Key
+			src.Index = append(na.fp.index[:len(na.fp.index):len(na.fp.index)], src.Index...)
+			md.Fields = append(md.Fields, src)
+		}
+		for _, k := range nmd.Unused {
+			md.Unused = append(md.Unused, na.fp.key+"."+k)
+		}
+	}
+	return nil
+}
+
+// errors returns the errors of filling the nested option struct,
+// qualified by the field holding it.
+func (na *nestedArguments) errors(err error) []error {
+	list, ok := err.(ErrorList)
+	if !ok {
+		list = ErrorList{err}
+	}
+
+	out := make([]error, len(list))
+	for i, err := range list {
+		arg := na.p.keyword[0]
+		e := newFieldError(arg.value, err).at(arg)
+		e.Field, e.Key, e.prefix = na.fp.name, na.fp.key, na.fp.key+": "
+		if fe, ok := err.(*FieldError); ok {
+			if fe.Field != "" {
+				e.Field += "." + fe.Field
+			}
+			if fe.Key != "" {
+				e.Key += "." + fe.Key
+			}
+			e.Pos, e.End, e.Value = fe.Pos, fe.End, fe.Value
+		}
+		out[i] = e
+	}
+	return out
+}
+
+// plan returns the decode plan of the option struct type t.
+func (d *Decoder) plan(t reflect.Type) *structPlan {
+	if sp, ok := d.plans.Load(t); ok {
+		return sp.(*structPlan)
+	}
+
+	sp := d.newPlan(t)
+	actual, _ := d.plans.LoadOrStore(t, sp)
+	return actual.(*structPlan)
+}
+
+func (d *Decoder) newPlan(t reflect.Type) *structPlan {
+	sp := &structPlan{keyword: map[string]*fieldPlan{}, ignoreUnknown: d.ignoreUnknown, merge: d.merge, presence: &d.presence}
+	depth := map[string]int{} // depth of the fields in sp.keyword.
+	for _, f := range flattenFields(t) {
+		fp, err := d.fieldPlan(f, sp.numFields)
+		if err != nil {
+			sp.err = newKindError(ErrInvalidOptionStruct, "option struct %s: field %s: %v", t, f.Name, err)
+			return sp
+		}
+		sp.numFields++
+
+		if fp.elem != nil {
+			if sp.remainder != nil {
+				sp.err = newKindError(ErrInvalidOptionStruct, "option struct %s: fields %s and %s are both remainders", t, sp.remainder.name, f.Name)
+				return sp
+			}
+			sp.remainder = fp
+			continue
+		}
+
+		if fp.rest {
+			if sp.rest != nil {
+				sp.err = newKindError(ErrInvalidOptionStruct, "option struct %s: fields %s and %s both take the rest", t, sp.rest.name, f.Name)
+				return sp
+			}
+			sp.rest = fp
+		} else if sp.rest == nil && f.IsExported() {
+			// Unexported fields cannot be filled and take no slot.
+			sp.positional = append(sp.positional, fp)
+		}
+		if f.IsExported() {
+			// Shallower fields hide deeper ones, fields of the same
+			// depth make their key ambiguous.
+			switch other, ok := sp.keyword[fp.key]; {
+			case !ok || len(f.Index) < depth[fp.key]:
+				sp.keyword[fp.key], depth[fp.key] = fp, len(f.Index)
+				delete(sp.ambiguous, fp.key)
+			case depth[fp.key] == len(f.Index):
+				if sp.ambiguous == nil {
+					sp.ambiguous = map[string]string{}
+				}
+				if _, ok := sp.ambiguous[fp.key]; !ok {
+					sp.ambiguous[fp.key] = other.name
+				}
+				sp.ambiguous[fp.key] += " and " + fp.name
+			}
+		} else {
+			fp.key = ""
+		}
+		if fp.required || fp.def != nil || len(fp.constraints) > 0 {
+			sp.checked = append(sp.checked, fp)
+		}
+	}
+	for key := range sp.ambiguous {
+		delete(sp.keyword, key)
+	}
+	sp.validate = reflect.PointerTo(t).Implements(validatorType)
+	return sp
+}
+
+// flattenFields returns the fields of the struct type t in order, with
+// the fields of embedded structs in place of the embedded fields. The
+// names of the fields of embedded structs are qualified by the path
+// leading to them. Opaque embedded structs are kept as single fields.
+func flattenFields(t reflect.Type) []reflect.StructField {
+	var walk func(t reflect.Type, index []int, prefix string, visiting map[reflect.Type]bool) []reflect.StructField
+	walk = func(t reflect.Type, index []int, prefix string, visiting map[reflect.Type]bool) (out []reflect.StructField) {
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			f.Index = append(index[:len(index):len(index)], i)
+			if f.Anonymous {
+				if et := indirectType(f.Type); et.Kind() == reflect.Struct && et != valueType && !isOpaque(et) {
+					if !visiting[et] {
+						out = append(out, walk(et, f.Index, prefix+f.Name+".", visiting)...)
+					}
+					continue
+				}
+			}
+			f.Name = prefix + f.Name
+			out = append(out, f)
+		}
+		return out
+	}
+	return walk(t, nil, "", map[reflect.Type]bool{})
+}
+
+// isOpaque reports whether the struct type t, when embedded, is a value
+// of its own rather than a group of options: it implements
+// encoding.TextUnmarshaler, like time.Time, or has no exported fields.
+func isOpaque(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() || f.Anonymous {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Decoder) fieldPlan(f reflect.StructField, id int) (*fieldPlan, error) {
+	meta, err := parseFieldMeta(f.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	fp := &fieldPlan{
+		id:       id,
+		name:     f.Name,
+		key:      d.naming(f.Name[strings.LastIndexByte(f.Name, '.')+1:]),
+		index:    f.Index,
+		decode:   d.decoder(f.Type),
+		required: meta.required,
+		def:      meta.def,
+	}
+	if fp.required && fp.def != nil {
+		return nil, fmt.Errorf("required field cannot have a default value")
+	}
+	if meta.rest {
+		if f.Type.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("rest field must be a slice, not %s", f.Type)
+		}
+		fp.rest = true
+	}
+	if meta.remainder {
+		if f.Type.Kind() != reflect.Map || f.Type.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("remainder field must be a map with string keys, not %s", f.Type)
+		}
+		fp.elem = d.decoder(f.Type.Elem())
+	}
+	if et := indirectType(f.Type); et.Kind() == reflect.Struct && et != valueType && f.IsExported() {
+		// Plans are looked up lazily to allow for recursive types.
+		fp.nested = func() *structPlan { return d.plan(et) }
+	}
+	if fp.constraints, err = d.newConstraints(f.Type, meta); err != nil {
+		return nil, err
+	}
+	if fp.elem != nil && (fp.required || fp.def != nil || len(fp.constraints) > 0) {
+		// The remainder is filled from unknown keys only, nothing
+		// would ever check these options.
+		return nil, fmt.Errorf("remainder field cannot be required, have a default value or constraints")
+	}
+	if fp.def != nil {
+		// Catch broken default values early.
+		v := reflect.New(f.Type).Elem()
+		if err := fp.decode(v, fp.def); err != nil {
+			return nil, fmt.Errorf("bad default value: %w", err)
+		}
+		if msg := checkConstraints(fp.constraints, v); msg != "" {
+			return nil, fmt.Errorf("bad default value: %s %s", fp.def, msg)
+		}
+	}
+	return fp, nil
+}
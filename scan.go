@@ -0,0 +1,82 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldOptions holds the options filled from the tag of a single
+// struct field found by ScanStruct.
+type FieldOptions[T any] struct {
+	Field   reflect.StructField // the tagged field.
+	Index   []int               // index sequence of the field for reflect.Value.FieldByIndex.
+	Options T                   // options filled from the tag of the field.
+}
+
+// ScanStruct fills an option struct of type T from the tag named key of
+// every field of the struct type typ, which may also be a pointer to a
+// struct type. Fields of untagged embedded structs are scanned as if
+// they were fields of typ itself, fields without the tag or with a tag
+// value of "-" are skipped.
+//
+// All fields are scanned even if some of them fail, in which case the
+// errors are returned together as an ErrorList naming each field.
+func ScanStruct[T any](typ reflect.Type, key string) ([]FieldOptions[T], error) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("scan target must be a struct type, not %s", typ)
+	}
+
+	s := &structScanner[T]{key: key, visiting: map[reflect.Type]bool{}}
+	s.scan(typ, nil, "")
+	return s.out, s.errs.Err()
+}
+
+// structScanner holds the state of a single ScanStruct invocation.
+type structScanner[T any] struct {
+	key      string
+	visiting map[reflect.Type]bool // guards against recursive embedding.
+	out      []FieldOptions[T]
+	errs     ErrorList
+}
+
+func (s *structScanner[T]) scan(typ reflect.Type, index []int, prefix string) {
+	s.visiting[typ] = true
+	defer delete(s.visiting, typ)
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		fieldIndex := append(index[:len(index):len(index)], i)
+		name := prefix + f.Name
+
+		tag, found := Lookup(f.Tag, s.key)
+		if !found {
+			if f.Anonymous {
+				if et := indirectType(f.Type); et.Kind() == reflect.Struct && !s.visiting[et] {
+					s.scan(et, fieldIndex, name+".")
+				}
+			}
+			continue
+		}
+		if tag.Value == "-" {
+			continue
+		}
+
+		fo := FieldOptions[T]{Field: f, Index: fieldIndex}
+		if err := tag.Fill(&fo.Options); err != nil {
+			s.errs = append(s.errs, fmt.Errorf("field %s: %w", name, err))
+			continue
+		}
+		s.out = append(s.out, fo)
+	}
+}
+
+// indirectType returns the element type of pointer types and t otherwise.
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,78 @@
+package stragts
+
+import (
+	"reflect"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestScanStruct(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TagStruct struct {
+		Index    string
+		Priority int
+	}
+
+	type Base struct {
+		ID int `norm:"pk,priority=1"`
+	}
+
+	type Audit struct {
+		Created string `norm:"created,priority=9"`
+	}
+
+	type Model struct {
+		Base
+		*Audit
+		Name    string `norm:"idx_name,priority=2"`
+		Skipped string `norm:"-"`
+		Plain   string
+		Other   string `json:"other"`
+	}
+
+	fields, err := ScanStruct[TagStruct](reflect.TypeOf(&Model{}), "norm")
+	if !assert.NoError(err) || !assert.Len(fields, 3) {
+		return
+	}
+
+	assert.Equal("ID", fields[0].Field.Name)
+	assert.Equal([]int{0, 0}, fields[0].Index)
+	assert.Equal(TagStruct{Index: "pk", Priority: 1}, fields[0].Options)
+
+	assert.Equal("Created", fields[1].Field.Name)
+	assert.Equal([]int{1, 0}, fields[1].Index)
+	assert.Equal(TagStruct{Index: "created", Priority: 9}, fields[1].Options)
+
+	assert.Equal("Name", fields[2].Field.Name)
+	assert.Equal([]int{2}, fields[2].Index)
+	assert.Equal(TagStruct{Index: "idx_name", Priority: 2}, fields[2].Options)
+
+	v := reflect.ValueOf(Model{Base: Base{ID: 7}})
+	assert.Equal(7, v.FieldByIndex(fields[0].Index).Interface())
+}
+
+func TestScanStruct_Errors(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type Broken struct {
+		A string `norm:"a,,"`
+		B string `norm:"b"`
+		C string `norm:"'c"`
+	}
+
+	fields, err := ScanStruct[struct{ Index string }](reflect.TypeOf(Broken{}), "norm")
+
+	var errs ErrorList
+	if assert.ErrorAs(err, &errs) && assert.Len(errs, 2) {
+		assert.Contains(errs[0].Error(), "field A:")
+		assert.Contains(errs[1].Error(), "field C:")
+	}
+	if assert.Len(fields, 1) {
+		assert.Equal("b", fields[0].Options.Index)
+	}
+
+	_, err = ScanStruct[struct{}](reflect.TypeOf(42), "norm")
+	assert.Error(err)
+}
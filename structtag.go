@@ -0,0 +1,251 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StructTag is an editable struct tag in the conventional
+// `key:"value" key2:"value2"` format. The order of the keys is retained.
+type StructTag struct {
+	tags []Tag
+}
+
+// NewStructTag parses the given struct tag into an editable StructTag.
+// Malformed struct tags are reported as errors, see ParseStructTag.
+func NewStructTag(tag reflect.StructTag) (*StructTag, error) {
+	tags, err := ParseStructTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	return &StructTag{tags: tags}, nil
+}
+
+// Keys returns the keys of the struct tag in order.
+func (st *StructTag) Keys() []string {
+	keys := make([]string, len(st.tags))
+	for i, t := range st.tags {
+		keys[i] = t.Name
+	}
+	return keys
+}
+
+// Lookup returns the tag stored under the given key.
+func (st *StructTag) Lookup(key string) (t *Tag, ok bool) {
+	if i := st.index(key); i >= 0 {
+		t, ok = &Tag{Name: key, Value: st.tags[i].Value}, true
+	}
+	return
+}
+
+// Set stores the raw value under the given key, replacing an existing
+// value in place or appending a new key to the end of the struct tag.
+func (st *StructTag) Set(key, value string) error {
+	if !isStructTagKey(key) {
+		return fmt.Errorf("invalid struct tag key %q", key)
+	}
+	if i := st.index(key); i >= 0 {
+		st.tags[i].Value = value
+	} else {
+		st.tags = append(st.tags, Tag{Name: key, Value: value})
+	}
+	return nil
+}
+
+// Delete removes the given key from the struct tag.
+func (st *StructTag) Delete(key string) {
+	if i := st.index(key); i >= 0 {
+		st.tags = append(st.tags[:i], st.tags[i+1:]...)
+	}
+}
+
+// SetKey sets the keyword argument argKey within the value stored under
+// key to v, adding key to the struct tag if necessary. See SetKey.
+func (st *StructTag) SetKey(key, argKey string, v any) error {
+	return st.edit(key, true, func(value string) (string, error) {
+		return SetKey(value, argKey, v)
+	})
+}
+
+// RenameKey renames the keyword argument oldKey within the value stored
+// under key to newKey. See RenameKey.
+func (st *StructTag) RenameKey(key, oldKey, newKey string) error {
+	return st.edit(key, false, func(value string) (string, error) {
+		return RenameKey(value, oldKey, newKey)
+	})
+}
+
+// DeleteKey removes the keyword argument argKey from the value stored
+// under key. See DeleteKey.
+func (st *StructTag) DeleteKey(key, argKey string) error {
+	return st.edit(key, false, func(value string) (string, error) {
+		return DeleteKey(value, argKey)
+	})
+}
+
+// StructTag returns the struct tag in the conventional format.
+func (st *StructTag) StructTag() reflect.StructTag {
+	return reflect.StructTag(st.String())
+}
+
+// String returns the struct tag in the conventional format.
+func (st *StructTag) String() string {
+	var sb strings.Builder
+	for i, t := range st.tags {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t.Name)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Quote(t.Value))
+	}
+	return sb.String()
+}
+
+// Literal returns the struct tag as a Go string literal, suitable as
+// the value of an ast.BasicLit. A raw string literal is used whenever
+// the struct tag can be represented as one.
+func (st *StructTag) Literal() string {
+	s := st.String()
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+func (st *StructTag) edit(key string, create bool, fn func(value string) (string, error)) error {
+	i := st.index(key)
+	if i < 0 && !create {
+		return fmt.Errorf("struct tag key %q not found", key)
+	}
+
+	var value string
+	if i >= 0 {
+		value = st.tags[i].Value
+	}
+	value, err := fn(value)
+	if err != nil {
+		return fmt.Errorf("struct tag key %q: %w", key, err)
+	}
+	return st.Set(key, value)
+}
+
+func (st *StructTag) index(key string) int {
+	for i, t := range st.tags {
+		if t.Name == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// StructTagError describes a violation of the conventional struct tag
+// format as found by ParseStructTag.
+type StructTagError struct {
+	Offset int    // byte offset in the struct tag at which the error occurred.
+	Key    string // key being parsed, if known.
+	Msg    string // description of the error.
+	Err    error  // underlying error, if any.
+}
+
+func (e *StructTagError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("struct tag: ")
+	if e.Key != "" {
+		sb.WriteString("key ")
+		sb.WriteString(strconv.Quote(e.Key))
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Msg)
+	sb.WriteString(" at offset ")
+	sb.WriteString(strconv.Itoa(e.Offset))
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+}
+
+func (e *StructTagError) Unwrap() error { return e.Err }
+
+// ParseStructTag splits a struct tag in the conventional format into
+// all of its key value pairs in order. It follows the rules of
+// reflect.StructTag.Lookup but, instead of silently ignoring the rest
+// of a malformed struct tag, reports the violation as *StructTagError.
+// Duplicate keys are reported as well.
+func ParseStructTag(st reflect.StructTag) (tags []Tag, err error) {
+	tag, offset := string(st), 0
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		offset, tag = offset+i, tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && isStructTagKeyChar(tag[i]) {
+			i++
+		}
+		if i == 0 {
+			return nil, &StructTagError{Offset: offset, Msg: fmt.Sprintf("bad character %q", tag[0])}
+		}
+		name := tag[:i]
+		if i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, &StructTagError{Offset: offset + i, Key: name, Msg: "missing quoted value"}
+		}
+		for _, t := range tags {
+			if t.Name == name {
+				return nil, &StructTagError{Offset: offset, Key: name, Msg: "duplicate key"}
+			}
+		}
+		offset, tag = offset+i+1, tag[i+1:]
+
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, &StructTagError{Offset: offset, Key: name, Msg: "unterminated value"}
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, &StructTagError{Offset: offset, Key: name, Msg: "bad value", Err: err}
+		}
+		tags = append(tags, Tag{Name: name, Value: value})
+		offset, tag = offset+i+1, tag[i+1:]
+
+		if tag != "" && tag[0] != ' ' {
+			return nil, &StructTagError{Offset: offset, Key: name, Msg: "missing space after value"}
+		}
+	}
+	return tags, nil
+}
+
+// isStructTagKeyChar reports whether c may appear in a struct tag key.
+func isStructTagKeyChar(c byte) bool {
+	return c > ' ' && c != ':' && c != '"' && c != 0x7f
+}
+
+// isStructTagKey reports whether s can be used as a struct tag key.
+func isStructTagKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isStructTagKeyChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package stragts
+
+import (
+	"reflect"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestStructTag(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	st, err := NewStructTag(`json:"name,omitempty" norm:"index=idx_member, priority=2"`)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([]string{"json", "norm"}, st.Keys())
+
+	tag, ok := st.Lookup("norm")
+	if assert.True(ok) {
+		assert.Equal(&Tag{Name: "norm", Value: "index=idx_member, priority=2"}, tag)
+	}
+
+	assert.NoError(st.SetKey("norm", "priority", 3))
+	assert.NoError(st.RenameKey("norm", "index", "name"))
+	assert.NoError(st.SetKey("db", "column", "member_name"))
+	assert.NoError(st.Set("json", "name"))
+	assert.Equal(`json:"name" norm:"name=idx_member, priority=3" db:"column='member_name'"`, st.String())
+
+	assert.NoError(st.DeleteKey("norm", "priority"))
+	st.Delete("json")
+	assert.Equal(reflect.StructTag(`norm:"name=idx_member" db:"column='member_name'"`), st.StructTag())
+	assert.Equal("`norm:\"name=idx_member\" db:\"column='member_name'\"`", st.Literal())
+
+	value, _ := st.StructTag().Lookup("db")
+	assert.Equal("column='member_name'", value)
+
+	assert.Error(st.Set("bad key", "x"))
+	assert.Error(st.RenameKey("json", "a", "b"))
+	assert.Error(st.SetKey("norm", "priority", map[int]int{}))
+
+	st.tags = []Tag{{Name: "doc", Value: "a `raw` value"}}
+	assert.Equal("\"doc:\\\"a `raw` value\\\"\"", st.Literal())
+}
+
+func TestNewStructTag_Errors(t *testing.T) {
+	for _, tag := range []reflect.StructTag{
+		`norm: "x"`,
+		`norm:"x`,
+		`norm:"x"json:"y"`,
+		`:"x"`,
+		`norm`,
+		`norm:"\q"`,
+	} {
+		_, err := NewStructTag(tag)
+		assertpkg.Error(t, err, "NewStructTag(%v)", tag)
+	}
+}
+
+func TestParseStructTag(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	tags, err := ParseStructTag(`json:"name"  norm:"12,~switch"`)
+	if assert.NoError(err) {
+		assert.Equal([]Tag{{Name: "json", Value: "name"}, {Name: "norm", Value: "12,~switch"}}, tags)
+
+		var v struct {
+			NumField int
+			Switch   bool
+		}
+		assert.NoError(tags[1].Fill(&v))
+		assert.Equal(12, v.NumField)
+		assert.True(v.Switch)
+	}
+
+	tests := []struct {
+		tag    reflect.StructTag
+		offset int
+		key    string
+	}{
+		{tag: `json:"x" norm: "x"`, offset: 13, key: "norm"},
+		{tag: `json:"x" norm:"x`, offset: 14, key: "norm"},
+		{tag: `json:"x"norm:"y"`, offset: 8, key: "json"},
+		{tag: `json:"x" :"y"`, offset: 9},
+		{tag: `json:"x" json:"y"`, offset: 9, key: "json"},
+		{tag: `norm:"\q"`, offset: 5, key: "norm"},
+	}
+	for _, tt := range tests {
+		_, err := ParseStructTag(tt.tag)
+
+		var stErr *StructTagError
+		if assert.ErrorAs(err, &stErr, "ParseStructTag(%v)", tt.tag) {
+			assert.Equal(tt.offset, stErr.Offset, "ParseStructTag(%v)", tt.tag)
+			assert.Equal(tt.key, stErr.Key, "ParseStructTag(%v)", tt.tag)
+		}
+	}
+}
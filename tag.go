@@ -3,6 +3,7 @@ package stragts
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 )
 
 type Tag struct {
@@ -10,77 +11,65 @@ type Tag struct {
 	Value string
 }
 
-func applyNode(f reflect.Value, n node) {
-	if f.Kind() == reflect.Pointer && f.IsZero() {
-		f.Set(reflect.New(f.Type().Elem()))
-		f = f.Elem()
-	}
-	switch nv := n.(type) {
-	case *boolNode:
-		f.SetBool(nv.value)
-	case *switchNode:
-		f.SetBool(nv.value.value)
-	case *identifierNode:
-		f.SetString(nv.value)
-	case *nilNode:
-		f.Set(reflect.Zero(f.Type()))
-	case *numberNode:
-		switch {
-		case nv.IsInt:
-			f.SetInt(nv.Int64)
-		case nv.IsUint:
-			f.SetUint(nv.Uint64)
-		case nv.IsFloat:
-			f.SetFloat(nv.Float64)
-		}
-	case *sliceNode:
-		f.Set(reflect.MakeSlice(f.Type(), len(nv.values), len(nv.values)))
-		for i, el := range nv.values {
-			applyNode(f.Index(i), el)
-		}
-	case *stringNode:
-		f.SetString(nv.Text)
-	}
+func (tag Tag) Fill(model any) error {
+	return defaultDecoder.Fill(tag, model)
 }
 
-func (tag Tag) Fill(model any) error {
+// FillMetadata is like Fill but also returns which fields have been
+// set by which argument of the tag. See Decoder.FillMetadata.
+func (tag Tag) FillMetadata(model any) (*Metadata, error) {
+	return defaultDecoder.FillMetadata(tag, model)
+}
+
+// fillTarget returns the structure or map value model points to.
+func fillTarget(model any) (reflect.Value, error) {
 	// Ensure we're working directly on a reference to a structure
 	// value that is held by the call side and not a copy.
 	m := reflect.ValueOf(model)
-	if !m.IsValid() || m.IsNil() || m.Type().Kind() != reflect.Ptr || m.Type().Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("fill target must be a pointer to a struct, not %T", model)
-	}
-	m = m.Elem()
-
-	// Skip parsing the tag value if it is just a dash.
-	if tag.Value == "-" {
-		return nil
+	if m.IsValid() && m.Kind() == reflect.Ptr && !m.IsNil() {
+		switch et := m.Type().Elem(); et.Kind() {
+		case reflect.Struct:
+			return m.Elem(), nil
+		case reflect.Map:
+			if et.Key().Kind() == reflect.String {
+				return m.Elem(), nil
+			}
+		}
 	}
+	return reflect.Value{}, fmt.Errorf("fill target must be a pointer to a struct or to a map with string keys, not %T", model)
+}
 
-	// Processing of the tag flags, nothing special here.
-	p, err := parseValue(tag.Value)
-	if err != nil {
-		return err
+func Lookup(tags reflect.StructTag, key string) (t *Tag, ok bool) {
+	value, ok := tags.Lookup(key)
+	if ok {
+		t = &Tag{Name: key, Value: value}
 	}
+	return
+}
 
-	for i, n := range p.indexed {
-		applyNode(m.Field(i), n)
-	}
+// Decode returns a new option struct of type T filled from tag.
+func Decode[T any](tag Tag) (T, error) {
+	var v T
+	err := tag.Fill(&v)
+	return v, err
+}
 
-	for k, v := range p.keyword {
-		f := m.FieldByNameFunc(func(s string) bool {
-			return toKebabCase(s) == k
-		})
-		applyNode(f, v)
+// MustDecode is like Decode but panics if the tag cannot be decoded.
+func MustDecode[T any](tag Tag) T {
+	v, err := Decode[T](tag)
+	if err != nil {
+		panic(`stragts: MustDecode(` + strconv.Quote(tag.Value) + `): ` + err.Error())
 	}
-
-	return nil
+	return v
 }
 
-func Lookup(tags reflect.StructTag, key string) (t *Tag, ok bool) {
-	value, ok := tags.Lookup(key)
+// LookupDecode looks up the tag named key in tags and decodes it into
+// a new option struct of type T. The ok result reports whether the tag
+// is present, its options are only valid if ok is true.
+func LookupDecode[T any](tags reflect.StructTag, key string) (v T, ok bool, err error) {
+	tag, ok := Lookup(tags, key)
 	if ok {
-		t = &Tag{Name: key, Value: value}
+		v, err = Decode[T](*tag)
 	}
 	return
 }
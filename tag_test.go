@@ -1,6 +1,7 @@
 package stragts
 
 import (
+	"reflect"
 	"testing"
 
 	assertpkg "github.com/stretchr/testify/assert"
@@ -39,4 +40,78 @@ func TestTag_Fill(t *testing.T) {
 	tag = Tag{Value: "slice='foo';'baa'"}
 	assert.NoError(tag.Fill(v))
 	assert.Equal([]string{"foo", "baa"}, v.Slice)
+
+	// White space around arguments, separators and "=" is ignored.
+	v = &TestStruct{}
+	tag = Tag{Value: " 7 , false , ~switch , slice = 'a b' ; c "}
+	assert.NoError(tag.Fill(v))
+	assert.Equal(7, v.NumField)
+	assert.False(v.BoolField)
+	assert.True(*v.Switch)
+	assert.Equal([]string{"a b", "c"}, v.Slice)
+
+	assert.Error(Tag{Value: "12 13"}.Fill(v))
+	assert.Error(Tag{Value: "~ switch"}.Fill(v))
+}
+
+func BenchmarkTag_Fill(b *testing.B) {
+	type TestStruct struct {
+		Index       string
+		Priority    int
+		Unique      bool
+		StringField string
+		Slice       []string
+	}
+
+	tag := Tag{Value: "idx_member,2,~unique,string-field='hello world',slice=a;b"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v TestStruct
+		if err := tag.Fill(&v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type TagStruct struct {
+		Index    string
+		Priority int
+	}
+
+	v, err := Decode[TagStruct](Tag{Value: "idx_member,priority=2"})
+	if assert.NoError(err) {
+		assert.Equal(TagStruct{Index: "idx_member", Priority: 2}, v)
+	}
+
+	_, err = Decode[TagStruct](Tag{Value: "priority='x'"})
+	assert.Error(err)
+
+	_, err = Decode[int](Tag{Value: "1"})
+	assert.Error(err)
+
+	assert.Equal(TagStruct{Index: "a"}, MustDecode[TagStruct](Tag{Value: "a"}))
+	assert.Panics(func() { MustDecode[TagStruct](Tag{Value: "a,,"}) })
+
+	type TaggedStruct struct {
+		Name   string `norm:"index=idx_member,priority=2"`
+		Number string `norm:"priority=x;y"`
+	}
+	typ := reflect.TypeOf(TaggedStruct{})
+
+	v, ok, err := LookupDecode[TagStruct](typ.Field(0).Tag, "norm")
+	if assert.True(ok) && assert.NoError(err) {
+		assert.Equal(TagStruct{Index: "idx_member", Priority: 2}, v)
+	}
+
+	_, ok, err = LookupDecode[TagStruct](typ.Field(1).Tag, "norm")
+	assert.True(ok)
+	assert.Error(err)
+
+	_, ok, err = LookupDecode[TagStruct](typ.Field(0).Tag, "json")
+	assert.False(ok)
+	assert.NoError(err)
 }
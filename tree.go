@@ -2,12 +2,14 @@ package stragts
 
 import (
 	"fmt"
+	"runtime"
 	"strconv"
 )
 
 // tree is the representation of a single parsed tag.
 type tree struct {
 	root *listNode // top-level root of the tree.
+	text string    // text parsed to create the tree.
 
 	// Parsing only; cleared after parse.
 	lex       *lexer
@@ -46,24 +48,49 @@ func (t *tree) peek() item {
 	return t.token[0]
 }
 
-// errorf formats the error and terminates processing.
-func (t *tree) errorf(format string, args ...any) {
+// errorf formats the error spanning the token and terminates processing.
+func (t *tree) errorf(token item, format string, args ...any) {
 	t.root = nil
-	panic(fmt.Errorf(format, args...))
+	end := token.pos + pos(len(token.val))
+	if token.typ == itemError {
+		end = token.end
+	}
+	panic(&SyntaxError{Pos: int(token.pos), End: int(end), Msg: fmt.Sprintf(format, args...)})
 }
 
-func (t *tree) error(err error) {
-	t.errorf("%s", err)
+func (t *tree) error(token item, err error) {
+	t.errorf(token, "%s", err)
 }
 
 // unexpected complains about the token and terminates processing.
 func (t *tree) unexpected(token item) {
-	t.errorf("unexpected %s", token)
+	if token.typ == itemError {
+		t.errorf(token, "%s", token)
+	}
+	t.errorf(token, "unexpected %s", token)
+}
+
+// recover is the handler that turns panics into returns from the top level of Parse.
+func (t *tree) recover(errp *error) {
+	e := recover()
+	if e != nil {
+		if _, ok := e.(runtime.Error); ok {
+			panic(e)
+		}
+		if t.lex != nil {
+			t.lex.drain()
+			t.lex = nil
+		}
+		*errp = e.(error)
+	}
 }
 
 func (t *tree) startParse(text string) (tree *tree, err error) {
+	defer t.recover(&err)
+	t.text = text
 	t.lex = lex(text)
 	t.parse()
+	t.lex = nil
 	return t, nil
 }
 
@@ -79,6 +106,8 @@ Loop:
 			continue Loop
 		case itemEOF:
 			break Loop
+		default:
+			t.unexpected(token)
 		}
 	}
 }
@@ -110,17 +139,17 @@ func (t *tree) argument() *argumentNode {
 }
 
 func (t *tree) argumentValue() node {
-	prev := t.next()
-	nextToken := t.peek()
-	t.backup2(prev)
-	if nextToken.typ == itemListSeparator {
-		return t.slice()
+	value := t.simpleValue()
+	if t.peek().typ == itemListSeparator {
+		return t.slice(value)
 	}
-	return t.simpleValue()
+	return value
 }
 
 func (t *tree) simpleValue() node {
 	switch t.peek().typ {
+	case itemDisable, itemEnable:
+		return t.switchNode()
 	case itemNil:
 		return t.nil()
 	case itemBool:
@@ -158,7 +187,7 @@ func (t *tree) string() *stringNode {
 	token := t.next()
 	s, err := strconv.Unquote("\"" + token.val[1:len(token.val)-1] + "\"")
 	if err != nil {
-		t.error(err)
+		t.error(token, err)
 	}
 
 	return t.newString(token.pos, token.val, s)
@@ -168,13 +197,14 @@ func (t *tree) number() node {
 	token := t.next()
 	number, err := t.newNumber(token.pos, token.val)
 	if err != nil {
-		t.error(err)
+		t.error(token, err)
 	}
 	return number
 }
 
 func (t *tree) switchArgument() *argumentNode {
 	sn := t.switchNode()
+	sn.argument = true
 	return t.newArgument(sn.pos, sn.ident, sn)
 }
 
@@ -197,8 +227,8 @@ func (t *tree) switchNode() *switchNode {
 	return t.newSwitch(prefix.pos, t.identifier(), value)
 }
 
-func (t *tree) slice() node {
-	items := []node{t.simpleValue()}
+func (t *tree) slice(first node) node {
+	items := []node{first}
 	for t.next().typ == itemListSeparator {
 		items = append(items, t.simpleValue())
 	}
@@ -206,5 +236,11 @@ func (t *tree) slice() node {
 	return t.newSlice(items[0].getPosition(), items)
 }
 
-func newTree() *tree                  { return &tree{} }
+func newTree() *tree { return &tree{} }
+
+// Parse parses the tag value inp. Arguments are separated by commas and
+// list elements by semicolons. White space (spaces, tabs and newlines)
+// is allowed around arguments, list elements, separators and the "="
+// of keyword arguments, but not within a value or between a switch
+// prefix and its identifier, so "a = 1 ; 2 , ~b" equals "a=1;2,~b".
 func Parse(inp string) (*tree, error) { return newTree().startParse(inp) }
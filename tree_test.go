@@ -110,6 +110,13 @@ func TestParse(t *testing.T) {
 		{inp: "foo='hello world';'foo bar'",
 			want:    "<arg [:foo]=<slice#2 <string 'hello world'><string 'foo bar'>>>",
 			wantErr: assert.NoError},
+
+		{inp: "foo=!baa",
+			want:    "<arg [:foo]=<switch !<ident baa>>>",
+			wantErr: assert.NoError},
+		{inp: "foo=baa;!baz;~qux",
+			want:    "<arg [:foo]=<slice#3 <ident baa><switch !<ident baz>><switch ~<ident qux>>>>",
+			wantErr: assert.NoError},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -123,3 +130,38 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_Whitespace(t *testing.T) {
+	tests := []struct {
+		inp     string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{inp: " foo ", want: "foo", wantErr: assert.NoError},
+		{inp: "foo , baa", want: "foo,baa", wantErr: assert.NoError},
+		{inp: "\tfoo\t=\t'a b'\t", want: "foo='a b'", wantErr: assert.NoError},
+		{inp: "foo = 1 ; 2 ;\n3", want: "foo=1;2;3", wantErr: assert.NoError},
+		{inp: "a , ~b , !c", want: "a,~b,!c", wantErr: assert.NoError},
+		{inp: "a , c = !d", want: "a,c=!d", wantErr: assert.NoError},
+		{inp: "db.name = users", want: "db.name=users", wantErr: assert.NoError},
+		{inp: "foo , ", want: "foo", wantErr: assert.NoError},
+
+		{inp: "foo baa", wantErr: assert.Error},
+		{inp: "foo = 1 2", wantErr: assert.Error},
+		{inp: "~ foo", wantErr: assert.Error},
+		{inp: "foo = ! baa", wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.inp, func(t *testing.T) {
+			got, err := Parse(tt.inp)
+			if !tt.wantErr(t, err, fmt.Sprintf("Parse(%q)", tt.inp)) || err != nil {
+				return
+			}
+			want, err := Parse(tt.want)
+			if assert.NoError(t, err) {
+				assert.Equalf(t, graph(want.root), graph(got.root), "Parse(%q)", tt.inp)
+			}
+			assert.Equalf(t, tt.want, got.root.String(), "Parse(%q)", tt.inp)
+		})
+	}
+}
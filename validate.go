@@ -0,0 +1,202 @@
+package stragts
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Validator is implemented by option structs that check their own
+// values. Validate is called after the option struct has been filled
+// successfully.
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// constraint checks the value of a field against a declared constraint
+// and returns a description of the violation, if any.
+type constraint func(v reflect.Value) string
+
+// newConstraints returns the constraints declared by meta for fields of
+// the type t.
+func (d *Decoder) newConstraints(t reflect.Type, meta fieldMeta) (cs []constraint, err error) {
+	et := indirectType(t)
+
+	add := func(name string, n *numberNode, applies func(reflect.Type) bool, check func(v reflect.Value, n *numberNode) string) {
+		switch {
+		case n == nil || err != nil:
+		case !applies(et):
+			err = fmt.Errorf("field option %q does not apply to %s", name, t)
+		default:
+			cs = append(cs, func(v reflect.Value) string { return check(v, n) })
+		}
+	}
+	isBounded := func(t reflect.Type) bool { return isOrdered(t) || hasLen(t) }
+
+	add("min", meta.min, isBounded, func(v reflect.Value, n *numberNode) string {
+		if compare(v, n) < 0 {
+			return "is less than min=" + n.Text
+		}
+		return ""
+	})
+	add("max", meta.max, isBounded, func(v reflect.Value, n *numberNode) string {
+		if compare(v, n) > 0 {
+			return "exceeds max=" + n.Text
+		}
+		return ""
+	})
+	add("len", meta.len, hasLen, func(v reflect.Value, n *numberNode) string {
+		if !n.IsInt || int64(v.Len()) != n.Int64 {
+			return fmt.Sprintf("has length %d, want len=%s", v.Len(), n.Text)
+		}
+		return ""
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(meta.oneof) > 0 {
+		if !et.Comparable() {
+			return nil, fmt.Errorf("field option %q does not apply to %s", "oneof", t)
+		}
+		choices := make([]any, len(meta.oneof))
+		names := make([]string, len(meta.oneof))
+		decode := d.decoder(et)
+		for i, n := range meta.oneof {
+			v := reflect.New(et).Elem()
+			if err := decode(v, n); err != nil {
+				return nil, fmt.Errorf("bad oneof value: %w", err)
+			}
+			choices[i], names[i] = v.Interface(), n.String()
+		}
+		cs = append(cs, func(v reflect.Value) string {
+			x := v.Interface()
+			for _, c := range choices {
+				if equalAny(x, c) {
+					return ""
+				}
+			}
+			return "is not one of " + strings.Join(names, ", ")
+		})
+	}
+
+	if meta.pattern != "" {
+		if et.Kind() != reflect.String {
+			return nil, fmt.Errorf("field option %q does not apply to %s", "pattern", t)
+		}
+		re, err := regexp.Compile(meta.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern: %w", err)
+		}
+		cs = append(cs, func(v reflect.Value) string {
+			if !re.MatchString(v.String()) {
+				return "does not match pattern " + quote(meta.pattern)
+			}
+			return ""
+		})
+	}
+
+	return cs, nil
+}
+
+// checkConstraints returns the first violation of cs by v, if any.
+// Constraints apply to the value nil pointers point to and are not
+// checked for nil pointers.
+func checkConstraints(cs []constraint, v reflect.Value) string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	for _, c := range cs {
+		if msg := c(v); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// compare compares the number or the length of v with n.
+func compare(v reflect.Value, n *numberNode) int {
+	switch {
+	case hasLen(v.Type()):
+		return compareFloat(float64(v.Len()), n.Float64)
+	case v.CanInt() && n.IsInt:
+		return compareInt(v.Int(), n.Int64)
+	case v.CanUint() && n.IsUint:
+		return compareUint(v.Uint(), n.Uint64)
+	case v.CanUint() && !n.IsUint && n.Float64 < 0:
+		return 1
+	case v.CanFloat():
+		return compareFloat(v.Float(), n.Float64)
+	case v.CanInt():
+		return compareFloat(float64(v.Int()), n.Float64)
+	default:
+		return compareFloat(float64(v.Uint()), n.Float64)
+	}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// isOrdered reports whether values of t are numbers.
+func isOrdered(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// hasLen reports whether values of t have a length.
+func hasLen(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// equalAny reports whether x and y are equal. Unlike ==, it does not panic
+// for values of a type that is not comparable, such as a list held by
+// an interface field, but reports them as unequal.
+func equalAny(x, y any) bool {
+	t := reflect.TypeOf(x)
+	if t != reflect.TypeOf(y) || (t != nil && !t.Comparable()) {
+		return false
+	}
+	return x == y
+}
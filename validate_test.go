@@ -0,0 +1,114 @@
+package stragts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type testValidated struct {
+	Min int
+	Max int
+}
+
+func (v testValidated) Validate() error {
+	if v.Min > v.Max {
+		return errors.New("min exceeds max")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	_, err := Decode[testValidated](Tag{Value: "1,2"})
+	assert.NoError(err)
+
+	_, err = Decode[testValidated](Tag{Value: "3,2"})
+	assert.EqualError(err, "min exceeds max")
+}
+
+func TestFieldMeta_Constraints(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type Level uint8
+
+	type TestStruct struct {
+		Priority int      `stragts:"min=1,max=10"`
+		Level    *Level   `stragts:"max=3"`
+		Ratio    float64  `stragts:"min=-0.5,max=0.5"`
+		Name     string   `stragts:"pattern='^idx_',max=8"`
+		Columns  []string `stragts:"min=1,max=2"`
+		Pair     []int    `stragts:"len=2"`
+		Order    string   `stragts:"oneof=asc;desc,default=asc"`
+		Count    uint     `stragts:"min=-1,max=1e3"`
+	}
+
+	v, err := Decode[TestStruct](Tag{Value: "priority=10,level=3,ratio=-0.5,name=idx_a,columns=a;b,pair=1;2,count=1000"})
+	if assert.NoError(err) {
+		assert.Equal("asc", v.Order)
+	}
+
+	for value, msg := range map[string]string{
+		"priority=0":           `key "priority" at position 9: 0 is less than min=1`,
+		"priority=11":          `key "priority" at position 9: 11 exceeds max=10`,
+		"level=4":              `key "level" at position 6: 4 exceeds max=3`,
+		"ratio=0.75":           `key "ratio" at position 6: 0.75 exceeds max=0.5`,
+		"name=member":          `key "name" at position 5: member does not match pattern '^idx_'`,
+		"name=idx_member":      `key "name" at position 5: idx_member exceeds max=8`,
+		"columns=nil":          `key "columns" at position 8: nil is less than min=1`,
+		"columns=a;b;c":        `key "columns" at position 8: a;b;c exceeds max=2`,
+		"pair=1":               `key "pair" at position 5: 1 has length 1, want len=2`,
+		"1,order=up":           `key "order" at position 8: up is not one of asc, desc`,
+		"priority=1,count=1e4": `key "count" at position 17: 1e4 exceeds max=1e3`,
+	} {
+		_, err := Decode[TestStruct](Tag{Value: value})
+		assert.EqualError(err, msg, value)
+	}
+
+	type BadDefault struct {
+		Priority int `stragts:"max=3,default=4"`
+	}
+	_, err = Decode[BadDefault](Tag{Value: ""})
+	assert.ErrorContains(err, "bad default value: 4 exceeds max=3")
+
+	type BadPattern struct {
+		Priority int `stragts:"pattern='x'"`
+	}
+	_, err = Decode[BadPattern](Tag{Value: ""})
+	assert.ErrorContains(err, `field option "pattern" does not apply to int`)
+
+	type BadLen struct {
+		Priority int `stragts:"len=1"`
+	}
+	_, err = Decode[BadLen](Tag{Value: ""})
+	assert.ErrorContains(err, `field option "len" does not apply to int`)
+
+	type BadOneOf struct {
+		Priority int `stragts:"oneof=a;b"`
+	}
+	_, err = Decode[BadOneOf](Tag{Value: ""})
+	assert.ErrorContains(err, "bad oneof value")
+}
+
+func TestFieldMeta_OneOfAny(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	type Options struct {
+		Value any `stragts:"oneof=a;b"`
+	}
+
+	var v Options
+	assert.NoError(Tag{Value: "value=a"}.Fill(&v))
+	assert.EqualError(Tag{Value: "value=x;y"}.Fill(&v), `key "value" at position 6: x;y is not one of a, b`)
+
+	// Lists are not comparable, they never match a choice.
+	d := NewDecoder(WithConverter(reflect.TypeOf(&v.Value).Elem(), func(from Value, to reflect.Type) (any, error) {
+		return []any{from.Interface()}, nil
+	}))
+	assert.NotPanics(func() {
+		assert.Error(d.Fill(Tag{Value: "value=a"}, &v))
+	})
+}
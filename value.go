@@ -0,0 +1,198 @@
+package stragts
+
+import (
+	"reflect"
+)
+
+// Kind identifies the kind of a Value.
+type Kind int
+
+const (
+	KindInvalid    Kind = iota // The zero Value.
+	KindNil                    // The untyped nil constant.
+	KindBool                   // A boolean constant or switch.
+	KindNumber                 // A number constant.
+	KindString                 // A quoted string.
+	KindIdentifier             // An unquoted identifier.
+	KindList                   // A list of values.
+	KindSwitch                 // A switch within a value, like the flags in "perm=all;!write".
+)
+
+var kindNames = [...]string{
+	KindInvalid:    "invalid",
+	KindNil:        "nil",
+	KindBool:       "bool",
+	KindNumber:     "number",
+	KindString:     "string",
+	KindIdentifier: "identifier",
+	KindList:       "list",
+	KindSwitch:     "switch",
+}
+
+func (k Kind) String() string {
+	if 0 <= int(k) && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "invalid"
+}
+
+// Value is a single value of a parsed tag, as handed to decode hooks.
+// Fields of option structs of the type Value receive the value as it
+// is. The zero Value represents no value.
+type Value struct {
+	n node
+}
+
+var valueType = reflect.TypeOf(Value{})
+
+// Kind returns the kind of v.
+func (v Value) Kind() Kind {
+	if v.n == nil {
+		return KindInvalid
+	}
+	switch v.n.getType() {
+	case nodeNil:
+		return KindNil
+	case nodeBool:
+		return KindBool
+	case nodeSwitch:
+		if v.n.(*switchNode).argument {
+			return KindBool
+		}
+		return KindSwitch
+	case nodeNumber:
+		return KindNumber
+	case nodeString:
+		return KindString
+	case nodeIdentifier:
+		return KindIdentifier
+	case nodeSlice:
+		return KindList
+	}
+	return KindInvalid
+}
+
+// Pos returns the byte position of v in the tag value.
+func (v Value) Pos() int {
+	if v.n == nil {
+		return 0
+	}
+	return int(v.n.getPosition())
+}
+
+// String returns the original text of v in the tag value.
+func (v Value) String() string {
+	if v.n == nil {
+		return "<invalid>"
+	}
+	return v.n.String()
+}
+
+// Bool returns the value of a boolean constant or of a switch argument
+// like "~foo". Switches within values, of kind KindSwitch, are no bools.
+func (v Value) Bool() (b, ok bool) {
+	switch nv := v.n.(type) {
+	case *boolNode:
+		return nv.value, true
+	case *switchNode:
+		if nv.argument {
+			return nv.value.value, true
+		}
+	}
+	return false, false
+}
+
+// Text returns the name of an identifier or the unquoted text of a string.
+func (v Value) Text() (s string, ok bool) {
+	switch nv := v.n.(type) {
+	case *identifierNode:
+		return nv.value, true
+	case *stringNode:
+		return nv.Text, true
+	}
+	return "", false
+}
+
+// Int returns the value of a number that is representable as int64.
+func (v Value) Int() (i int64, ok bool) {
+	if nv, isNumber := v.n.(*numberNode); isNumber && nv.IsInt {
+		return nv.Int64, true
+	}
+	return 0, false
+}
+
+// Uint returns the value of a number that is representable as uint64.
+func (v Value) Uint() (u uint64, ok bool) {
+	if nv, isNumber := v.n.(*numberNode); isNumber && nv.IsUint {
+		return nv.Uint64, true
+	}
+	return 0, false
+}
+
+// Float returns the value of a number that is representable as float64.
+func (v Value) Float() (f float64, ok bool) {
+	if nv, isNumber := v.n.(*numberNode); isNumber && nv.IsFloat {
+		return nv.Float64, true
+	}
+	return 0, false
+}
+
+// List returns the elements of a list, or nil if v is not a list.
+func (v Value) List() []Value {
+	nv, ok := v.n.(*sliceNode)
+	if !ok {
+		return nil
+	}
+	values := make([]Value, len(nv.values))
+	for i, n := range nv.values {
+		values[i] = Value{n}
+	}
+	return values
+}
+
+// Interface returns the natural Go value of v: nil, a bool, an int64 for
+// numbers with an integral value that fits, a float64 for other numbers,
+// a string for strings and identifiers, or a []any for lists. Switches
+// within values have no natural Go value and yield nil.
+func (v Value) Interface() any {
+	switch nv := v.n.(type) {
+	case *boolNode:
+		return nv.value
+	case *switchNode:
+		if nv.argument {
+			return nv.value.value
+		}
+	case *numberNode:
+		if nv.IsInt {
+			return nv.Int64
+		}
+		return nv.Float64
+	case *stringNode:
+		return nv.Text
+	case *identifierNode:
+		return nv.value
+	case *sliceNode:
+		values := make([]any, len(nv.values))
+		for i, n := range nv.values {
+			values[i] = Value{n}.Interface()
+		}
+		return values
+	}
+	return nil
+}
+
+// hasSwitchValue reports whether v is or contains a switch within a
+// value.
+func (v Value) hasSwitchValue() bool {
+	switch v.Kind() {
+	case KindSwitch:
+		return true
+	case KindList:
+		for _, el := range v.List() {
+			if el.hasSwitchValue() {
+				return true
+			}
+		}
+	}
+	return false
+}